@@ -73,13 +73,13 @@ func startGroupcache(app *application, dogstatsd, mockDogstatsd, emfEnable, emfS
 	}
 
 	options := kubegroup.Options{
-		Client:                clientset,
-		Pool:                  pool,
-		LabelSelector:         "app=miniapi",
-		GroupCachePort:        app.groupCachePort,
-		Debug:                 debug,
-		DogstatsdClient:       dogstatsdClient,
-		ForceNamespaceDefault: true,
+		Client:          clientset,
+		Pool:            pool,
+		LabelSelector:   "app=miniapi",
+		GroupCachePort:  app.groupCachePort,
+		Debug:           debug,
+		DogstatsdClient: dogstatsdClient,
+		Namespace:       "default",
 	}
 
 	if app.registry != nil {
@@ -79,6 +79,8 @@ func main() {
 
 	app.serverMain = &http.Server{Addr: app.listenAddr, Handler: mux}
 
+	mux.Handle("/debug/kubegroup", app.group.Handler())
+
 	mux.HandleFunc("/", func(w http.ResponseWriter,
 		r *http.Request) {
 		routeHandler(w, r, app)
@@ -132,11 +134,14 @@ func shutdown(app *application) {
 
 	log.Printf("received signal '%v', initiating shutdown", sig)
 
-	log.Printf("stopping kubegroup")
+	log.Printf("draining kubegroup")
 
-	app.group.Close() // release kubegroup resources
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	time.Sleep(time.Second) // give kubegroup time to log debug messages about exiting
+	if err := app.group.Drain(ctx); err != nil { // leave peering, then release kubegroup resources
+		log.Printf("kubegroup drain error: %v", err)
+	}
 
 	log.Printf("stopping http servers")
 
@@ -19,13 +19,11 @@ func startGroupcache(app *application, dogstatsd, mockDogstatsd bool) {
 	// create groupcache instance
 	//
 
-	myIP, errAddr := kubegroup.FindMyAddress()
+	myAddr, errAddr := kubegroup.FindMyHostPort(app.groupCachePort)
 	if errAddr != nil {
 		log.Fatalf("find my address: %v", errAddr)
 	}
 
-	myAddr := myIP + app.groupCachePort
-
 	daemon, errDaemon := groupcache.ListenAndServe(context.TODO(), myAddr, groupcache.Options{})
 	if errDaemon != nil {
 		log.Fatalf("groupcache daemon: %v", errDaemon)
@@ -60,13 +58,13 @@ func startGroupcache(app *application, dogstatsd, mockDogstatsd bool) {
 	}
 
 	options := kubegroup.Options{
-		Client:                clientset,
-		Peers:                 daemon,
-		LabelSelector:         "app=miniapi",
-		GroupCachePort:        app.groupCachePort,
-		Debug:                 debug,
-		DogstatsdClient:       dogstatsdClient,
-		ForceNamespaceDefault: true,
+		Client:          clientset,
+		Peers:           daemon,
+		LabelSelector:   "app=miniapi",
+		GroupCachePort:  app.groupCachePort,
+		Debug:           debug,
+		DogstatsdClient: dogstatsdClient,
+		Namespace:       "default",
 	}
 
 	if app.registry != nil {
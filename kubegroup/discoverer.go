@@ -0,0 +1,114 @@
+package kubegroup
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// Discoverer is a source of peers. Set Options.Discoverer to drive
+// UpdatePeers from a custom source. If Options.Discoverer is undefined,
+// kubegroup watches PODs matching Options.LabelSelector, or the
+// EndpointSlices of Options.ServiceName.
+type Discoverer interface {
+	// Run starts discovery and blocks until ctx is canceled or discovery
+	// fails. Whenever peers change, Run calls onUpdate with the full list
+	// of peer candidates. Canceling ctx stops discovery; Run is called
+	// again if it exits while kubegroup is still running.
+	Run(ctx context.Context, onUpdate func(peers []PeerCandidate)) error
+}
+
+// PeerCandidate is a peer reported by a Discoverer.
+type PeerCandidate struct {
+	// Name identifies the peer, usually by POD name.
+	Name string
+
+	// Namespace is the POD namespace, if any.
+	Namespace string
+
+	// UID is the POD UID, if any. When both UID and the current POD's
+	// UID are known, they identify the current POD among peers.
+	UID string
+
+	// IP is the peer IP address, or host name.
+	IP string
+
+	// IPs optionally lists all peer addresses, for dual-stack peers.
+	// Options.IPFamily selects the address used among IPs.
+	// If IPs is empty, IP is used.
+	IPs []string
+
+	// Port is optional, like ":5000". Defaults to Options.GroupCachePort.
+	Port string
+
+	// Ready reports whether the peer should receive traffic.
+	// Only ready candidates are delivered as peers.
+	Ready bool
+
+	// Node is the name of the POD's node, if any.
+	Node string
+
+	// Zone is the POD's zone, if any. See Options.Topology.
+	Zone string
+
+	// Weight optionally defines the peer weight. Default is 1.
+	// See Options.WeightAnnotation.
+	Weight int
+
+	// Labels optionally holds POD labels. See Options.PeerURLTemplate.
+	Labels map[string]string
+
+	// Annotations optionally holds POD annotations.
+	// See Options.ExcludeAnnotation.
+	Annotations map[string]string
+
+	// ExcludeReason optionally excludes the candidate from peering.
+	// The reason is reported in debug logs and by Group.Peers.
+	ExcludeReason string
+}
+
+// multiDiscoverer runs several discoverers concurrently, reporting
+// the merged list of peers. Updates are held until every discoverer
+// has reported at least once, so a partial list is never delivered.
+// Run returns when any discoverer exits.
+type multiDiscoverer struct {
+	discoverers []Discoverer
+}
+
+// Run implements Discoverer.
+func (m *multiDiscoverer) Run(ctx context.Context, onUpdate func(peers []PeerCandidate)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mutex sync.Mutex
+	latest := make([][]PeerCandidate, len(m.discoverers))
+	reported := make([]bool, len(m.discoverers))
+	pending := len(m.discoverers)
+
+	errCh := make(chan error, len(m.discoverers))
+
+	for i, d := range m.discoverers {
+		go func() {
+			errCh <- d.Run(ctx, func(peers []PeerCandidate) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				latest[i] = peers
+				if !reported[i] {
+					reported[i] = true
+					pending--
+				}
+				if pending > 0 {
+					return // wait for all discoverers
+				}
+				onUpdate(slices.Concat(latest...))
+			})
+		}()
+	}
+
+	err := <-errCh
+	cancel()
+	for range len(m.discoverers) - 1 {
+		<-errCh
+	}
+	return err
+}
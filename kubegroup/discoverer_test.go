@@ -0,0 +1,66 @@
+package kubegroup
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// chanDiscoverer reports each peer list received from updates.
+type chanDiscoverer struct {
+	updates chan []PeerCandidate
+}
+
+func (d *chanDiscoverer) Run(ctx context.Context, onUpdate func(peers []PeerCandidate)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case peers := <-d.updates:
+			onUpdate(peers)
+		}
+	}
+}
+
+func TestMultiDiscovererWaitsForAll(t *testing.T) {
+	d1 := &chanDiscoverer{updates: make(chan []PeerCandidate)}
+	d2 := &chanDiscoverer{updates: make(chan []PeerCandidate)}
+	m := &multiDiscoverer{discoverers: []Discoverer{d1, d2}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results := make(chan []PeerCandidate, 10)
+	go m.Run(ctx, func(peers []PeerCandidate) { results <- peers })
+
+	d1.updates <- []PeerCandidate{{Name: "a"}}
+
+	select {
+	case peers := <-results:
+		t.Fatalf("unexpected partial update: %v", peers)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	d2.updates <- []PeerCandidate{{Name: "b"}}
+
+	select {
+	case peers := <-results:
+		if len(peers) != 2 {
+			t.Errorf("expected 2 merged peers, got %v", peers)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("missing merged update")
+	}
+
+	// later updates from a single discoverer are delivered
+	d1.updates <- []PeerCandidate{}
+
+	select {
+	case peers := <-results:
+		if len(peers) != 1 || peers[0].Name != "b" {
+			t.Errorf("expected peer b only, got %v", peers)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("missing update")
+	}
+}
@@ -0,0 +1,165 @@
+package kubegroup
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DNSResolver resolves DNS records for DNSDiscoverer.
+// *net.Resolver implements this interface.
+type DNSResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// DNSDiscovererOptions specifies options for NewDNSDiscoverer.
+type DNSDiscovererOptions struct {
+	// Host is required. It is the DNS name of a headless Service.
+	// Example: "my-app-headless.my-namespace.svc.cluster.local"
+	Host string
+
+	// SRVService optionally enables SRV lookup for _SRVService._SRVProto.Host,
+	// taking peer ports from SRV records. Example: "groupcache".
+	// If SRVService is undefined, A/AAAA records for Host are used and
+	// peer ports default to Options.GroupCachePort.
+	SRVService string
+
+	// SRVProto defaults to "tcp".
+	SRVProto string
+
+	// PollInterval defaults to 10 seconds.
+	PollInterval time.Duration
+
+	// Resolver defaults to net.DefaultResolver.
+	// Set Resolver to point lookups to a custom DNS server.
+	Resolver DNSResolver
+}
+
+// DNSDiscoverer discovers peers by periodically resolving a headless
+// Service. It does not require permissions against kubernetes API.
+// Set Options.Discoverer to a DNSDiscoverer to enable DNS discovery.
+type DNSDiscoverer struct {
+	options DNSDiscovererOptions
+}
+
+// NewDNSDiscoverer creates a DNSDiscoverer.
+func NewDNSDiscoverer(options DNSDiscovererOptions) *DNSDiscoverer {
+	if options.SRVProto == "" {
+		options.SRVProto = "tcp"
+	}
+	if options.PollInterval == 0 {
+		options.PollInterval = 10 * time.Second
+	}
+	if options.Resolver == nil {
+		options.Resolver = net.DefaultResolver
+	}
+	return &DNSDiscoverer{options: options}
+}
+
+// Run implements Discoverer. Run reports peers whenever resolved
+// addresses change. A failed lookup makes Run return the error.
+func (d *DNSDiscoverer) Run(ctx context.Context, onUpdate func(peers []PeerCandidate)) error {
+	if d.options.Host == "" {
+		return errors.New("dns discoverer: empty host")
+	}
+
+	ticker := time.NewTicker(d.options.PollInterval)
+	defer ticker.Stop()
+
+	var last []PeerCandidate
+
+	for {
+		candidates, err := d.resolve(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		if last == nil || !slices.EqualFunc(candidates, last, sameAddress) {
+			onUpdate(candidates)
+			last = candidates
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// resolve returns peers sorted by IP and port.
+// A name that does not exist yields no peers.
+func (d *DNSDiscoverer) resolve(ctx context.Context) ([]PeerCandidate, error) {
+	candidates := []PeerCandidate{}
+
+	if d.options.SRVService == "" {
+		addrs, err := d.lookupHost(ctx, d.options.Host)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			candidates = append(candidates, PeerCandidate{
+				Name:  addr,
+				IP:    addr,
+				Ready: true,
+			})
+		}
+	} else {
+		_, records, err := d.options.Resolver.LookupSRV(ctx,
+			d.options.SRVService, d.options.SRVProto, d.options.Host)
+		if err != nil && !isNotFound(err) {
+			return nil, err
+		}
+		for _, srv := range records {
+			addrs, err := d.lookupHost(ctx, srv.Target)
+			if err != nil {
+				return nil, err
+			}
+			name, _, _ := strings.Cut(srv.Target, ".")
+			for _, addr := range addrs {
+				candidates = append(candidates, PeerCandidate{
+					Name:  name,
+					IP:    addr,
+					Port:  ":" + strconv.Itoa(int(srv.Port)),
+					Ready: true,
+				})
+			}
+		}
+	}
+
+	slices.SortFunc(candidates, func(a, b PeerCandidate) int {
+		if c := strings.Compare(a.IP, b.IP); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Port, b.Port)
+	})
+
+	return slices.CompactFunc(candidates, func(a, b PeerCandidate) bool {
+		return a.IP == b.IP && a.Port == b.Port
+	}), nil
+}
+
+func (d *DNSDiscoverer) lookupHost(ctx context.Context, host string) ([]string, error) {
+	addrs, err := d.options.Resolver.LookupHost(ctx, host)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	return addrs, nil
+}
+
+func sameAddress(a, b PeerCandidate) bool {
+	return a.Name == b.Name && a.IP == b.IP && a.Port == b.Port
+}
+
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
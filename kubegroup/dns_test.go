@@ -0,0 +1,125 @@
+package kubegroup
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"testing"
+)
+
+// fakeResolver answers from fixed records. Missing names are NXDOMAIN.
+type fakeResolver struct {
+	hosts map[string][]string
+	srv   map[string][]*net.SRV // key: _service._proto.name
+	err   error
+}
+
+func (r *fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	addrs, found := r.hosts[host]
+	if !found {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return addrs, nil
+}
+
+func (r *fakeResolver) LookupSRV(_ context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	if r.err != nil {
+		return "", nil, r.err
+	}
+	cname := "_" + service + "._" + proto + "." + name
+	records, found := r.srv[cname]
+	if !found {
+		return "", nil, &net.DNSError{Err: "no such host", Name: cname, IsNotFound: true}
+	}
+	return cname, records, nil
+}
+
+func TestDNSResolveHost(t *testing.T) {
+	d := NewDNSDiscoverer(DNSDiscovererOptions{
+		Host: "app.ns.svc",
+		Resolver: &fakeResolver{hosts: map[string][]string{
+			"app.ns.svc": {"10.0.0.2", "10.0.0.1", "10.0.0.2"},
+		}},
+	})
+
+	peers, err := d.resolve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ips []string
+	for _, p := range peers {
+		ips = append(ips, p.IP)
+		if !p.Ready || p.Port != "" {
+			t.Errorf("unexpected peer: %+v", p)
+		}
+	}
+	if !slices.Equal(ips, []string{"10.0.0.1", "10.0.0.2"}) {
+		t.Errorf("expected sorted unique ips, got %v", ips)
+	}
+}
+
+func TestDNSResolveSRV(t *testing.T) {
+	d := NewDNSDiscoverer(DNSDiscovererOptions{
+		Host:       "app.ns.svc",
+		SRVService: "groupcache",
+		Resolver: &fakeResolver{
+			srv: map[string][]*net.SRV{
+				"_groupcache._tcp.app.ns.svc": {
+					{Target: "pod-b.app.ns.svc.", Port: 5001},
+					{Target: "pod-a.app.ns.svc.", Port: 5000},
+				},
+			},
+			hosts: map[string][]string{
+				"pod-a.app.ns.svc.": {"10.0.0.1"},
+				"pod-b.app.ns.svc.": {"10.0.0.2"},
+			},
+		},
+	})
+
+	peers, err := d.resolve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []PeerCandidate{
+		{Name: "pod-a", IP: "10.0.0.1", Port: ":5000", Ready: true},
+		{Name: "pod-b", IP: "10.0.0.2", Port: ":5001", Ready: true},
+	}
+	if !slices.EqualFunc(peers, want, sameAddress) {
+		t.Errorf("expected %v, got %v", want, peers)
+	}
+}
+
+func TestDNSResolveNotFound(t *testing.T) {
+	for _, srv := range []string{"", "groupcache"} {
+		d := NewDNSDiscoverer(DNSDiscovererOptions{
+			Host:       "missing.ns.svc",
+			SRVService: srv,
+			Resolver:   &fakeResolver{},
+		})
+
+		peers, err := d.resolve(context.Background())
+		if err != nil {
+			t.Errorf("srv=%q: NXDOMAIN must not fail: %v", srv, err)
+		}
+		if peers == nil || len(peers) != 0 {
+			t.Errorf("srv=%q: expected empty peer list, got %v", srv, peers)
+		}
+	}
+}
+
+func TestDNSResolveError(t *testing.T) {
+	d := NewDNSDiscoverer(DNSDiscovererOptions{
+		Host:     "app.ns.svc",
+		Resolver: &fakeResolver{err: errors.New("server failure")},
+	})
+
+	if _, err := d.resolve(context.Background()); err == nil {
+		t.Errorf("expected resolver error")
+	}
+}
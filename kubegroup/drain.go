@@ -0,0 +1,41 @@
+package kubegroup
+
+import (
+	"context"
+	"time"
+)
+
+// Drain gracefully removes the current POD from peering, for instance
+// on SIGTERM or in a preStop hook. Drain delivers the peer list without
+// the current POD, so that keys are no longer forwarded to it, waits
+// for Options.DrainGracePeriod, then stops discovery with Close.
+// If ctx is done before the grace period elapses, Drain closes the
+// group immediately and returns ctx error.
+//
+// groupcache3 (Peers) rejects peer lists without the current POD, so
+// for Peers Drain does not remove the current POD: it only waits for
+// the grace period, while other PODs drop the terminating POD as soon
+// as its deletion is observed.
+func (g *Group) Drain(ctx context.Context) error {
+	g.debugf("Drain called, grace period: %v", g.options.DrainGracePeriod)
+
+	g.draining.Store(true)
+
+	if g.options.Peers == nil {
+		if peers := g.Peers(); peers != nil {
+			g.deliver(peers) // deliver excludes self while draining
+		}
+	}
+
+	timer := time.NewTimer(g.options.DrainGracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		g.Close()
+		return ctx.Err()
+	case <-timer.C:
+	}
+
+	return g.Close()
+}
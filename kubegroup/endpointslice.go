@@ -0,0 +1,137 @@
+package kubegroup
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	listersdiscoveryv1 "k8s.io/client-go/listers/discovery/v1"
+)
+
+// endpointSliceDiscoverer watches EndpointSlices of a Service and
+// reports their endpoints as peers.
+type endpointSliceDiscoverer struct {
+	group     *Group
+	namespace string
+}
+
+// Run implements Discoverer.
+func (d *endpointSliceDiscoverer) Run(ctx context.Context, onUpdate func(peers []PeerCandidate)) error {
+	g := d.group
+
+	factory := informers.NewSharedInformerFactoryWithOptions(g.options.Client, 0,
+		informers.WithNamespace(d.namespace),
+		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+			o.LabelSelector = discoveryv1.LabelServiceName + "=" + g.options.ServiceName
+		}))
+
+	endpointSlices := factory.Discovery().V1().EndpointSlices()
+
+	name := fmt.Sprintf("endpointslice informer: namespace=%s service=%s",
+		d.namespace, g.options.ServiceName)
+
+	return g.watchInformer(ctx, name, factory, endpointSlices.Informer(),
+		func() ([]PeerCandidate, error) {
+			return d.list(endpointSlices.Lister())
+		}, onUpdate)
+}
+
+func (d *endpointSliceDiscoverer) list(lister listersdiscoveryv1.EndpointSliceLister) ([]PeerCandidate, error) {
+	list, err := lister.EndpointSlices(d.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	// IPv4 slices first, so dual-stack PODs list IPv4 address first.
+	slices.SortStableFunc(list, func(a, b *discoveryv1.EndpointSlice) int {
+		return strings.Compare(string(a.AddressType), string(b.AddressType))
+	})
+
+	var candidates []PeerCandidate
+	seen := map[string]int{} // key => index into candidates
+
+	for _, slice := range list {
+		if slice.AddressType == discoveryv1.AddressTypeFQDN {
+			continue
+		}
+
+		port, found := d.findPort(slice)
+		if !found {
+			d.group.debugf("endpointslice informer: slice=%s: port name '%s' not found",
+				slice.Name, d.group.options.ServicePortName)
+			continue
+		}
+
+		for _, ep := range slice.Endpoints {
+			if len(ep.Addresses) == 0 {
+				continue
+			}
+			c := PeerCandidate{
+				Namespace: slice.Namespace,
+				IP:        ep.Addresses[0],
+				Port:      port,
+				Ready:     endpointReady(ep.Conditions),
+			}
+			if ep.NodeName != nil {
+				c.Node = *ep.NodeName
+			}
+			if ep.Zone != nil {
+				c.Zone = *ep.Zone
+			}
+			if ep.TargetRef != nil {
+				c.Name = ep.TargetRef.Name
+				c.UID = string(ep.TargetRef.UID)
+			} else {
+				c.Name = c.IP
+			}
+			key := c.Namespace + "/" + c.Name
+			if i, dup := seen[key]; dup {
+				// dual-stack POD: same endpoint in another address family
+				candidates[i].IPs = append(candidates[i].IPs, c.IP)
+				continue
+			}
+			c.IPs = []string{c.IP}
+			seen[key] = len(candidates)
+			candidates = append(candidates, c)
+		}
+	}
+
+	return candidates, nil
+}
+
+// findPort returns the slice port named by ServicePortName, like ":5000".
+// Empty port means GroupCachePort.
+func (d *endpointSliceDiscoverer) findPort(slice *discoveryv1.EndpointSlice) (string, bool) {
+	name := d.group.options.ServicePortName
+	if name == "" {
+		return "", true
+	}
+	for _, p := range slice.Ports {
+		if p.Name != nil && *p.Name == name && p.Port != nil {
+			return ":" + strconv.Itoa(int(*p.Port)), true
+		}
+	}
+	return "", false
+}
+
+// endpointReady honors EndpointSlice conditions: a terminating endpoint
+// is never ready; a nil ready condition falls back to serving, then
+// to true, as specified by discovery.k8s.io/v1.
+func endpointReady(cond discoveryv1.EndpointConditions) bool {
+	if cond.Terminating != nil && *cond.Terminating {
+		return false
+	}
+	if cond.Ready != nil {
+		return *cond.Ready
+	}
+	if cond.Serving != nil {
+		return *cond.Serving
+	}
+	return true
+}
@@ -0,0 +1,175 @@
+package kubegroup
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	listersdiscoveryv1 "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newSliceLister(t *testing.T, slices ...*discoveryv1.EndpointSlice) listersdiscoveryv1.EndpointSliceLister {
+	t.Helper()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, s := range slices {
+		if err := indexer.Add(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return listersdiscoveryv1.NewEndpointSliceLister(indexer)
+}
+
+func newSlice(name string, addressType discoveryv1.AddressType,
+	endpoints ...discoveryv1.Endpoint) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta:  metav1.ObjectMeta{Namespace: "ns", Name: name},
+		AddressType: addressType,
+		Endpoints:   endpoints,
+	}
+}
+
+func podEndpoint(pod, addr string) discoveryv1.Endpoint {
+	return discoveryv1.Endpoint{
+		Addresses: []string{addr},
+		TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: pod, UID: types.UID(pod + "-uid")},
+	}
+}
+
+func TestEndpointSliceDualStack(t *testing.T) {
+	g := newTestGroup(t, Options{})
+	d := &endpointSliceDiscoverer{group: g, namespace: "ns"}
+
+	// IPv6 slice listed first; IPv4 address must still come first
+	lister := newSliceLister(t,
+		newSlice("svc-v6", discoveryv1.AddressTypeIPv6,
+			podEndpoint("pod-a", "fd00::1"), podEndpoint("pod-b", "fd00::2")),
+		newSlice("svc-v4", discoveryv1.AddressTypeIPv4,
+			podEndpoint("pod-a", "10.0.0.1")),
+	)
+
+	candidates, err := d.list(lister)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byName := map[string]PeerCandidate{}
+	for _, c := range candidates {
+		byName[c.Name] = c
+	}
+	if len(candidates) != 2 || len(byName) != 2 {
+		t.Fatalf("expected pods merged across slices, got %+v", candidates)
+	}
+
+	a := byName["pod-a"]
+	if a.IP != "10.0.0.1" || len(a.IPs) != 2 || a.IPs[0] != "10.0.0.1" || a.IPs[1] != "fd00::1" {
+		t.Errorf("pod-a: expected dual-stack IPv4 first, got %+v", a)
+	}
+	if a.UID != "pod-a-uid" || a.Namespace != "ns" {
+		t.Errorf("pod-a: unexpected identity: %+v", a)
+	}
+
+	b := byName["pod-b"]
+	if b.IP != "fd00::2" || len(b.IPs) != 1 {
+		t.Errorf("pod-b: expected single-stack IPv6, got %+v", b)
+	}
+
+	for _, family := range []IPFamily{IPFamilyIPv6, IPFamilyPreferIPv6} {
+		if got := selectIP(a.IPs, family); got != "fd00::1" {
+			t.Errorf("pod-a: family %s: expected fd00::1, got %s", family, got)
+		}
+	}
+}
+
+func TestEndpointReady(t *testing.T) {
+	yes, no := true, false
+
+	table := []struct {
+		name string
+		cond discoveryv1.EndpointConditions
+		want bool
+	}{
+		{"no conditions", discoveryv1.EndpointConditions{}, true},
+		{"ready", discoveryv1.EndpointConditions{Ready: &yes}, true},
+		{"not ready", discoveryv1.EndpointConditions{Ready: &no}, false},
+		{"not ready but serving", discoveryv1.EndpointConditions{Ready: &no, Serving: &yes}, false},
+		{"serving fallback", discoveryv1.EndpointConditions{Serving: &yes}, true},
+		{"not serving fallback", discoveryv1.EndpointConditions{Serving: &no}, false},
+		{"terminating", discoveryv1.EndpointConditions{Ready: &yes, Serving: &yes, Terminating: &yes}, false},
+		{"not terminating", discoveryv1.EndpointConditions{Ready: &yes, Terminating: &no}, true},
+	}
+
+	for _, data := range table {
+		if got := endpointReady(data.cond); got != data.want {
+			t.Errorf("%s: expected %t, got %t", data.name, data.want, got)
+		}
+	}
+}
+
+func TestEndpointSliceList(t *testing.T) {
+	g := newTestGroup(t, Options{ServicePortName: "groupcache"})
+	d := &endpointSliceDiscoverer{group: g, namespace: "ns"}
+
+	portName, otherName := "groupcache", "http"
+	port, otherPort := int32(5000), int32(8080)
+	node, zone := "node-1", "zone-a"
+	no := false
+
+	withPort := newSlice("svc-a", discoveryv1.AddressTypeIPv4,
+		discoveryv1.Endpoint{
+			Addresses: []string{"10.0.0.1"},
+			TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "pod-a", UID: "uid-a"},
+			NodeName:  &node,
+			Zone:      &zone,
+		},
+		discoveryv1.Endpoint{
+			Addresses:  []string{"10.0.0.2"}, // no target ref
+			Conditions: discoveryv1.EndpointConditions{Ready: &no},
+		},
+		discoveryv1.Endpoint{}, // no addresses
+	)
+	withPort.Ports = []discoveryv1.EndpointPort{
+		{Name: &otherName, Port: &otherPort},
+		{Name: &portName, Port: &port},
+	}
+
+	missingPort := newSlice("svc-b", discoveryv1.AddressTypeIPv4, podEndpoint("pod-c", "10.0.0.3"))
+	missingPort.Ports = []discoveryv1.EndpointPort{{Name: &otherName, Port: &otherPort}}
+
+	fqdn := newSlice("svc-c", discoveryv1.AddressTypeFQDN, podEndpoint("pod-d", "pod-d.example.com"))
+	fqdn.Ports = withPort.Ports
+
+	candidates, err := d.list(newSliceLister(t, withPort, missingPort, fqdn))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []PeerCandidate{
+		{Namespace: "ns", Name: "pod-a", UID: "uid-a", IP: "10.0.0.1", IPs: []string{"10.0.0.1"},
+			Port: ":5000", Ready: true, Node: "node-1", Zone: "zone-a"},
+		{Namespace: "ns", Name: "10.0.0.2", IP: "10.0.0.2", IPs: []string{"10.0.0.2"},
+			Port: ":5000", Ready: false},
+	}
+	if !reflect.DeepEqual(candidates, want) {
+		t.Errorf("expected %+v, got %+v", want, candidates)
+	}
+}
+
+func TestEndpointSliceListDefaultPort(t *testing.T) {
+	g := newTestGroup(t, Options{})
+	d := &endpointSliceDiscoverer{group: g, namespace: "ns"}
+
+	// without ServicePortName, slice ports are ignored
+	candidates, err := d.list(newSliceLister(t,
+		newSlice("svc-a", discoveryv1.AddressTypeIPv4, podEndpoint("pod-a", "10.0.0.1"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 1 || candidates[0].Port != "" {
+		t.Errorf("expected one peer on GroupCachePort, got %+v", candidates)
+	}
+}
@@ -0,0 +1,65 @@
+package kubegroup
+
+import "errors"
+
+var (
+	// ErrNoPeerTarget is returned when both Options.Pool and Options.Peers are nil.
+	ErrNoPeerTarget = errors.New("kubegroup: Pool and Peers are both nil")
+
+	// ErrNoClient is returned when Options.Client is nil.
+	ErrNoClient = errors.New("kubegroup: Client is nil")
+
+	// ErrInvalidPort is returned when Options.GroupCachePort is not a valid ":port".
+	ErrInvalidPort = errors.New("kubegroup: invalid GroupCachePort")
+
+	// ErrInvalidScheme is returned when Options.Scheme is neither "http" nor "https".
+	ErrInvalidScheme = errors.New("kubegroup: invalid Scheme")
+
+	// ErrInvalidPeerURLTemplate is returned when Options.PeerURLTemplate
+	// cannot be parsed.
+	ErrInvalidPeerURLTemplate = errors.New("kubegroup: invalid PeerURLTemplate")
+
+	// ErrInvalidIPFamily is returned when Options.IPFamily is unknown.
+	ErrInvalidIPFamily = errors.New("kubegroup: invalid IPFamily")
+
+	// ErrInvalidMaxWeight is returned when Options.MaxWeight is negative.
+	ErrInvalidMaxWeight = errors.New("kubegroup: invalid MaxWeight")
+
+	// ErrInvalidSelfAddressCIDR is returned when Options.SelfAddressCIDRs
+	// holds an invalid CIDR.
+	ErrInvalidSelfAddressCIDR = errors.New("kubegroup: invalid SelfAddressCIDRs")
+
+	// ErrInvalidTopology is returned when Options.Topology is unknown.
+	ErrInvalidTopology = errors.New("kubegroup: invalid Topology")
+
+	// ErrInvalidLabelSelector is returned when Options.LabelSelector is
+	// empty or cannot be parsed.
+	ErrInvalidLabelSelector = errors.New("kubegroup: invalid LabelSelector")
+
+	// ErrInvalidFieldSelector is returned when Options.FieldSelector
+	// cannot be parsed.
+	ErrInvalidFieldSelector = errors.New("kubegroup: invalid FieldSelector")
+
+	// ErrNoNamespace is returned when the current POD's namespace
+	// cannot be found.
+	ErrNoNamespace = errors.New("kubegroup: namespace not found")
+
+	// ErrNoSelfAddress is returned when the current POD's address
+	// cannot be detected.
+	ErrNoSelfAddress = errors.New("kubegroup: self address not found")
+
+	// ErrClosed is reported by Group.Err after Group.Close is called.
+	ErrClosed = errors.New("kubegroup: closed")
+
+	// ErrInformerExited is reported by Group.Err when the pod informer
+	// exits on its own without an error.
+	ErrInformerExited = errors.New("kubegroup: informer exited")
+
+	// ErrInformerMaxRestarts is reported by Group.Err when the pod informer
+	// exceeds Options.InformerMaxRestarts.
+	ErrInformerMaxRestarts = errors.New("kubegroup: informer max restarts exceeded")
+
+	// ErrNotSynced is returned by Group.WaitForSync when discovery exits
+	// before delivering the first peer list.
+	ErrNotSynced = errors.New("kubegroup: discovery exited before initial sync")
+)
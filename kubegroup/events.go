@@ -0,0 +1,91 @@
+package kubegroup
+
+import "slices"
+
+// PeerEvent describes a change in peer membership between two
+// consecutive peering updates.
+type PeerEvent struct {
+	// Added holds PODs that were not reported by the previous update.
+	Added []PeerInfo
+
+	// Removed holds PODs that are no longer reported.
+	Removed []PeerInfo
+
+	// ReadinessChanged holds PODs whose readiness flipped.
+	ReadinessChanged []PeerInfo
+
+	// Peers holds the full set of PODs reported by the update.
+	Peers []PeerInfo
+}
+
+func (e PeerEvent) empty() bool {
+	return len(e.Added) == 0 && len(e.Removed) == 0 &&
+		len(e.ReadinessChanged) == 0
+}
+
+type subscription struct {
+	fn func(PeerEvent)
+}
+
+// Subscribe registers fn to be called whenever peer membership changes.
+// fn is called sequentially from the discovery goroutine, so it should
+// not block; the PeerEvent slices must be treated as read-only.
+// Call the returned function to unsubscribe.
+func (g *Group) Subscribe(fn func(PeerEvent)) (unsubscribe func()) {
+	sub := &subscription{fn: fn}
+
+	g.subMutex.Lock()
+	g.subscriptions = append(g.subscriptions, sub)
+	g.subMutex.Unlock()
+
+	return func() {
+		g.subMutex.Lock()
+		g.subscriptions = slices.DeleteFunc(g.subscriptions,
+			func(s *subscription) bool { return s == sub })
+		g.subMutex.Unlock()
+	}
+}
+
+func (g *Group) publish(ev PeerEvent) {
+	g.subMutex.Lock()
+	subs := slices.Clone(g.subscriptions)
+	g.subMutex.Unlock()
+
+	for _, s := range subs {
+		s.fn(ev)
+	}
+}
+
+// diffPeers computes changes from prev to curr, keyed by POD
+// namespace and name.
+func diffPeers(prev, curr []PeerInfo) PeerEvent {
+	key := func(p PeerInfo) string { return p.Namespace + "/" + p.Name }
+
+	before := make(map[string]PeerInfo, len(prev))
+	for _, p := range prev {
+		before[key(p)] = p
+	}
+
+	ev := PeerEvent{Peers: curr}
+
+	seen := make(map[string]struct{}, len(curr))
+	for _, p := range curr {
+		k := key(p)
+		seen[k] = struct{}{}
+		old, found := before[k]
+		switch {
+		case !found:
+			ev.Added = append(ev.Added, p)
+		case old.Ready != p.Ready:
+			ev.ReadinessChanged = append(ev.ReadinessChanged, p)
+		}
+	}
+
+	for _, p := range prev {
+		if _, found := seen[key(p)]; !found {
+			ev.Removed = append(ev.Removed, p)
+		}
+	}
+
+	return ev
+}
@@ -0,0 +1,76 @@
+package kubegroup
+
+import (
+	"slices"
+	"testing"
+)
+
+func names(peers []PeerInfo) []string {
+	var list []string
+	for _, p := range peers {
+		list = append(list, p.Namespace+"/"+p.Name)
+	}
+	return list
+}
+
+func TestDiffPeers(t *testing.T) {
+	prev := []PeerInfo{
+		{Namespace: "ns", Name: "a", Ready: true},
+		{Namespace: "ns", Name: "b", Ready: true},
+		{Namespace: "ns", Name: "c", Ready: false},
+	}
+	curr := []PeerInfo{
+		{Namespace: "ns", Name: "a", Ready: true, IP: "10.0.0.9"}, // unrelated change
+		{Namespace: "ns", Name: "c", Ready: true},
+		{Namespace: "ns", Name: "d", Ready: false},
+		{Namespace: "other", Name: "a", Ready: true},
+	}
+
+	ev := diffPeers(prev, curr)
+
+	if got := names(ev.Added); !slices.Equal(got, []string{"ns/d", "other/a"}) {
+		t.Errorf("added: %v", got)
+	}
+	if got := names(ev.Removed); !slices.Equal(got, []string{"ns/b"}) {
+		t.Errorf("removed: %v", got)
+	}
+	if got := names(ev.ReadinessChanged); !slices.Equal(got, []string{"ns/c"}) {
+		t.Errorf("readiness changed: %v", got)
+	}
+	if len(ev.Peers) != len(curr) {
+		t.Errorf("peers: expected %d, got %d", len(curr), len(ev.Peers))
+	}
+	if ev.empty() {
+		t.Errorf("event must not be empty")
+	}
+}
+
+func TestDiffPeersUnchanged(t *testing.T) {
+	peers := []PeerInfo{{Namespace: "ns", Name: "a", Ready: true}}
+	if ev := diffPeers(peers, peers); !ev.empty() {
+		t.Errorf("expected empty event, got %+v", ev)
+	}
+}
+
+func TestDiffPeersInitial(t *testing.T) {
+	curr := []PeerInfo{{Namespace: "ns", Name: "a"}, {Namespace: "ns", Name: "b"}}
+	ev := diffPeers(nil, curr)
+	if got := names(ev.Added); !slices.Equal(got, []string{"ns/a", "ns/b"}) {
+		t.Errorf("added: %v", got)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	g := &Group{}
+
+	var got []PeerEvent
+	unsubscribe := g.Subscribe(func(ev PeerEvent) { got = append(got, ev) })
+
+	g.publish(PeerEvent{})
+	unsubscribe()
+	g.publish(PeerEvent{})
+
+	if len(got) != 1 {
+		t.Errorf("expected 1 event before unsubscribe, got %d", len(got))
+	}
+}
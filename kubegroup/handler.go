@@ -0,0 +1,222 @@
+package kubegroup
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// groupStatus holds counters exposed by Group.Handler.
+type groupStatus struct {
+	Updates       int       `json:"updates"`
+	Deliveries    int       `json:"deliveries"`
+	Suppressed    int       `json:"suppressed"`
+	LastUpdate    time.Time `json:"last_update"`
+	LastError     string    `json:"last_error,omitempty"`
+	LastErrorTime time.Time `json:"last_error_time"`
+}
+
+// debugOptions holds the non-sensitive subset of Options.
+type debugOptions struct {
+	Target                  string        `json:"target"`
+	Discoverer              string        `json:"discoverer"`
+	GroupCachePort          string        `json:"groupcache_port"`
+	Scheme                  string        `json:"scheme"`
+	PeerURLTemplate         string        `json:"peer_url_template,omitempty"`
+	MaxWeight               int           `json:"max_weight"`
+	VirtualPeers            bool          `json:"virtual_peers"`
+	Topology                string        `json:"topology,omitempty"`
+	TopologyMinPeers        int           `json:"topology_min_peers"`
+	LabelSelector           string        `json:"label_selector"`
+	FieldSelector           string        `json:"field_selector,omitempty"`
+	PortName                string        `json:"port_name,omitempty"`
+	ServiceName             string        `json:"service_name,omitempty"`
+	ServicePortName         string        `json:"service_port_name,omitempty"`
+	Debug                   bool          `json:"debug"`
+	MetricsNamespace        string        `json:"metrics_namespace"`
+	DogstatsdEnabled        bool          `json:"dogstatsd_enabled"`
+	EmfEnabled              bool          `json:"emf_enabled"`
+	DebounceDelay           time.Duration `json:"debounce_delay"`
+	SetPeersTimeout         time.Duration `json:"set_peers_timeout"`
+	InformerRestartMinDelay time.Duration `json:"informer_restart_min_delay"`
+	InformerRestartMaxDelay time.Duration `json:"informer_restart_max_delay"`
+	InformerMaxRestarts     int           `json:"informer_max_restarts"`
+	WaitInitialSync         bool          `json:"wait_initial_sync"`
+}
+
+// debugState is rendered by Group.Handler.
+type debugState struct {
+	Options       debugOptions   `json:"options"`
+	Namespaces    []string       `json:"namespaces"`
+	LabelSelector string         `json:"label_selector"`
+	SelfAddress   string         `json:"self_address"`
+	SelfMethod    string         `json:"self_address_method"`
+	SelfZone      string         `json:"self_zone,omitempty"`
+	Zones         map[string]int `json:"zones,omitempty"`
+	Pods          []PeerInfo     `json:"pods"`
+	Delivered     []PeerInfo     `json:"delivered"`
+	Status        groupStatus    `json:"status"`
+	Running       bool           `json:"running"`
+	Draining      bool           `json:"draining"`
+	Err           string         `json:"error,omitempty"`
+}
+
+// Handler returns an http.Handler that renders kubegroup state, for
+// instance to be mounted at /debug/kubegroup. The state is rendered
+// as JSON, or as HTML when the query parameter format=html is given
+// or the client accepts text/html.
+func (g *Group) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := g.debugState()
+
+		if r.URL.Query().Get("format") == "html" ||
+			strings.Contains(r.Header.Get("Accept"), "text/html") {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			if err := debugTemplate.Execute(w, state); err != nil {
+				g.errorf("debug handler: html: %v", err)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(state); err != nil {
+			g.errorf("debug handler: json: %v", err)
+		}
+	})
+}
+
+func (g *Group) debugState() debugState {
+	o := g.options
+
+	target := "groupcache2"
+	if o.Peers != nil {
+		target = "groupcache3"
+	}
+
+	pods := g.Peers()
+
+	state := debugState{
+		Options: debugOptions{
+			Target:                  target,
+			Discoverer:              fmt.Sprintf("%T", g.discoverer),
+			GroupCachePort:          o.GroupCachePort,
+			Scheme:                  o.Scheme,
+			PeerURLTemplate:         o.PeerURLTemplate,
+			MaxWeight:               o.MaxWeight,
+			VirtualPeers:            o.VirtualPeers,
+			Topology:                string(o.Topology),
+			TopologyMinPeers:        o.TopologyMinPeers,
+			LabelSelector:           o.LabelSelector,
+			FieldSelector:           o.FieldSelector,
+			PortName:                o.PortName,
+			ServiceName:             o.ServiceName,
+			ServicePortName:         o.ServicePortName,
+			Debug:                   o.Debug,
+			MetricsNamespace:        o.MetricsNamespace,
+			DogstatsdEnabled:        o.DogstatsdClient != nil,
+			EmfEnabled:              o.EmfEnable,
+			DebounceDelay:           o.DebounceDelay,
+			SetPeersTimeout:         o.SetPeersTimeout,
+			InformerRestartMinDelay: o.InformerRestartMinDelay,
+			InformerRestartMaxDelay: o.InformerRestartMaxDelay,
+			InformerMaxRestarts:     o.InformerMaxRestarts,
+			WaitInitialSync:         o.WaitInitialSync,
+		},
+		Namespaces:    g.debugNamespaces(),
+		LabelSelector: o.LabelSelector,
+		SelfAddress:   g.myAddr,
+		SelfMethod:    string(g.myAddrMethod),
+		Pods:          pods,
+		SelfZone:      g.selfZone(pods),
+		Zones:         zonePeers(pods),
+		Delivered:     g.deliveredPeers(),
+		Status:        g.getStatus(),
+		Draining:      g.draining.Load(),
+	}
+
+	select {
+	case <-g.done:
+		state.Err = fmt.Sprint(g.Err())
+	default:
+		state.Running = true
+	}
+
+	return state
+}
+
+// debugNamespaces shows all namespaces as "*".
+func (g *Group) debugNamespaces() []string {
+	namespaces := make([]string, 0, len(g.namespaces))
+	for _, ns := range g.namespaces {
+		if ns == metav1.NamespaceAll {
+			ns = "*"
+		}
+		namespaces = append(namespaces, ns)
+	}
+	return namespaces
+}
+
+func (g *Group) deliveredPeers() []PeerInfo {
+	g.deliverMutex.Lock()
+	defer g.deliverMutex.Unlock()
+	return append([]PeerInfo{}, g.delivered...)
+}
+
+func (g *Group) getStatus() groupStatus {
+	g.statusMutex.Lock()
+	defer g.statusMutex.Unlock()
+	return g.status
+}
+
+func (g *Group) updateStatus(f func(s *groupStatus)) {
+	g.statusMutex.Lock()
+	f(&g.status)
+	g.statusMutex.Unlock()
+}
+
+var debugTemplate = template.Must(template.New("kubegroup").Parse(`<!DOCTYPE html>
+<html>
+<head><title>kubegroup</title></head>
+<body>
+<h1>kubegroup</h1>
+<table border="1">
+<tr><th>running</th><td>{{.Running}}</td></tr>
+<tr><th>draining</th><td>{{.Draining}}</td></tr>
+{{if .Err}}<tr><th>error</th><td>{{.Err}}</td></tr>{{end}}
+<tr><th>namespaces</th><td>{{range .Namespaces}}{{.}} {{end}}</td></tr>
+<tr><th>label selector</th><td>{{.LabelSelector}}</td></tr>
+<tr><th>self address</th><td>{{.SelfAddress}} (method: {{.SelfMethod}})</td></tr>
+<tr><th>target</th><td>{{.Options.Target}}</td></tr>
+<tr><th>groupcache port</th><td>{{.Options.GroupCachePort}}</td></tr>
+<tr><th>scheme</th><td>{{.Options.Scheme}}</td></tr>
+<tr><th>peer url template</th><td>{{.Options.PeerURLTemplate}}</td></tr>
+<tr><th>topology</th><td>{{.Options.Topology}} (min peers: {{.Options.TopologyMinPeers}})</td></tr>
+<tr><th>self zone</th><td>{{.SelfZone}}</td></tr>
+<tr><th>zones</th><td>{{range $zone, $n := .Zones}}{{$zone}}={{$n}} {{end}}</td></tr>
+<tr><th>max weight</th><td>{{.Options.MaxWeight}} (virtual peers: {{.Options.VirtualPeers}})</td></tr>
+<tr><th>updates</th><td>{{.Status.Updates}}</td></tr>
+<tr><th>deliveries</th><td>{{.Status.Deliveries}}</td></tr>
+<tr><th>suppressed</th><td>{{.Status.Suppressed}}</td></tr>
+<tr><th>last update</th><td>{{.Status.LastUpdate}}</td></tr>
+<tr><th>last error</th><td>{{.Status.LastError}} {{if .Status.LastError}}({{.Status.LastErrorTime}}){{end}}</td></tr>
+</table>
+<h2>PODs</h2>
+<table border="1">
+<tr><th>namespace</th><th>name</th><th>ip</th><th>address</th><th>ready</th><th>self</th><th>weight</th><th>node</th><th>zone</th></tr>
+{{range .Pods}}<tr><td>{{.Namespace}}</td><td>{{.Name}}</td><td>{{.IP}}</td><td>{{.Address}}</td><td>{{.Ready}}</td><td>{{.IsSelf}}</td><td>{{.Weight}}</td><td>{{.Node}}</td><td>{{.Zone}}</td></tr>
+{{end}}</table>
+<h2>Delivered peers</h2>
+<table border="1">
+<tr><th>namespace</th><th>name</th><th>address</th><th>self</th><th>weight</th><th>zone</th></tr>
+{{range .Delivered}}<tr><td>{{.Namespace}}</td><td>{{.Name}}</td><td>{{.Address}}</td><td>{{.IsSelf}}</td><td>{{.Weight}}</td><td>{{.Zone}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
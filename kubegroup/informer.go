@@ -0,0 +1,115 @@
+package kubegroup
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// watchInformer starts factory and, whenever informer reports changes,
+// calls list and delivers its result to onUpdate. Changes are debounced
+// by DebounceDelay. watchInformer blocks until ctx is done.
+// Optional related informers, started elsewhere, also trigger list when
+// objects are added or deleted, or when their labels change.
+func (g *Group) watchInformer(ctx context.Context, name string,
+	factory informers.SharedInformerFactory, informer cache.SharedIndexInformer,
+	list func() ([]PeerCandidate, error),
+	onUpdate func(peers []PeerCandidate),
+	related ...cache.SharedIndexInformer) error {
+
+	stop := ctx.Done()
+
+	defer factory.Shutdown()
+
+	trigger := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case trigger <- struct{}{}:
+		default:
+		}
+	}
+
+	_, errHandler := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(_ any) { notify() },
+		UpdateFunc: func(_, _ any) { notify() },
+		DeleteFunc: func(_ any) { notify() },
+	})
+	if errHandler != nil {
+		return errHandler
+	}
+
+	for _, r := range related {
+		reg, err := r.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(_ any) { notify() },
+			UpdateFunc: func(oldObj, newObj any) {
+				if labelsChanged(oldObj, newObj) {
+					notify()
+				}
+			},
+			DeleteFunc: func(_ any) { notify() },
+		})
+		if err != nil {
+			return err
+		}
+		defer r.RemoveEventHandler(reg)
+	}
+
+	factory.Start(stop)
+
+	for _, synced := range factory.WaitForCacheSync(stop) {
+		if !synced {
+			select {
+			case <-stop:
+				return nil
+			default:
+				return fmt.Errorf("%s: cache sync failed", name)
+			}
+		}
+	}
+
+	g.debugf("%s: synced", name)
+
+	notify() // deliver initial state even if empty
+
+	delay := g.options.DebounceDelay
+	if delay == 0 {
+		delay = 2 * time.Second
+	}
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-trigger:
+		}
+
+		// debounce
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(delay):
+		}
+
+		candidates, err := list()
+		if err != nil {
+			g.errorf("%s: list: %v", name, err)
+			continue
+		}
+		onUpdate(candidates)
+	}
+}
+
+// labelsChanged reports whether object labels differ.
+func labelsChanged(oldObj, newObj any) bool {
+	o, errOld := meta.Accessor(oldObj)
+	n, errNew := meta.Accessor(newObj)
+	if errOld != nil || errNew != nil {
+		return true
+	}
+	return !maps.Equal(o.GetLabels(), n.GetLabels())
+}
@@ -0,0 +1,80 @@
+package kubegroup
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// IPFamily selects the address family used to reach dual-stack peers.
+type IPFamily string
+
+const (
+	// IPFamilyDefault uses the peer's primary address.
+	IPFamilyDefault IPFamily = ""
+
+	// IPFamilyIPv4 uses only IPv4 addresses. Peers without IPv4 address are ignored.
+	IPFamilyIPv4 IPFamily = "IPv4"
+
+	// IPFamilyIPv6 uses only IPv6 addresses. Peers without IPv6 address are ignored.
+	IPFamilyIPv6 IPFamily = "IPv6"
+
+	// IPFamilyPreferIPv4 uses IPv4 address if available, otherwise IPv6.
+	IPFamilyPreferIPv4 IPFamily = "PreferIPv4"
+
+	// IPFamilyPreferIPv6 uses IPv6 address if available, otherwise IPv4.
+	IPFamilyPreferIPv6 IPFamily = "PreferIPv6"
+)
+
+func (f IPFamily) validate() error {
+	switch f {
+	case IPFamilyDefault, IPFamilyIPv4, IPFamilyIPv6,
+		IPFamilyPreferIPv4, IPFamilyPreferIPv6:
+		return nil
+	}
+	return fmt.Errorf("%w: '%s'", ErrInvalidIPFamily, f)
+}
+
+// selectIP picks the address from ips according to family.
+// Host names, which are not IP addresses, match any family.
+// It returns empty string if no address matches.
+func selectIP(ips []string, family IPFamily) string {
+	if len(ips) == 0 {
+		return ""
+	}
+
+	var v4, v6 string
+	for _, ip := range ips {
+		addr, err := netip.ParseAddr(ip)
+		switch {
+		case err != nil:
+			return ip // host name
+		case addr.Unmap().Is4():
+			if v4 == "" {
+				v4 = ip
+			}
+		default:
+			if v6 == "" {
+				v6 = ip
+			}
+		}
+	}
+
+	switch family {
+	case IPFamilyIPv4:
+		return v4
+	case IPFamilyIPv6:
+		return v6
+	case IPFamilyPreferIPv4:
+		if v4 != "" {
+			return v4
+		}
+		return v6
+	case IPFamilyPreferIPv6:
+		if v6 != "" {
+			return v6
+		}
+		return v4
+	}
+
+	return ips[0]
+}
@@ -0,0 +1,82 @@
+package kubegroup
+
+import "testing"
+
+func TestSelectIP(t *testing.T) {
+	dual := []string{"10.0.0.1", "fd00::1"}
+	dual6 := []string{"fd00::1", "10.0.0.1"}
+	v4 := []string{"10.0.0.1"}
+	v6 := []string{"fd00::1"}
+	mapped := []string{"::ffff:10.0.0.1"}
+	host := []string{"cache1"}
+
+	table := []struct {
+		ips    []string
+		family IPFamily
+		want   string
+	}{
+		{nil, IPFamilyDefault, ""},
+
+		{dual, IPFamilyDefault, "10.0.0.1"},
+		{dual6, IPFamilyDefault, "fd00::1"},
+		{dual, IPFamilyIPv4, "10.0.0.1"},
+		{dual6, IPFamilyIPv4, "10.0.0.1"},
+		{dual, IPFamilyIPv6, "fd00::1"},
+		{dual, IPFamilyPreferIPv4, "10.0.0.1"},
+		{dual, IPFamilyPreferIPv6, "fd00::1"},
+
+		{v4, IPFamilyIPv6, ""},
+		{v4, IPFamilyPreferIPv6, "10.0.0.1"},
+		{v6, IPFamilyIPv4, ""},
+		{v6, IPFamilyPreferIPv4, "fd00::1"},
+
+		{mapped, IPFamilyIPv4, "::ffff:10.0.0.1"},
+		{mapped, IPFamilyIPv6, ""},
+
+		{host, IPFamilyIPv4, "cache1"},
+		{host, IPFamilyIPv6, "cache1"},
+	}
+
+	for _, data := range table {
+		if got := selectIP(data.ips, data.family); got != data.want {
+			t.Errorf("ips=%v family=%q: expected %q, got %q",
+				data.ips, data.family, data.want, got)
+		}
+	}
+}
+
+func TestJoinHostPort(t *testing.T) {
+	table := []struct {
+		addr string
+		port string
+		want string
+	}{
+		{"10.0.0.1", ":5000", "10.0.0.1:5000"},
+		{"fd00::1", ":5000", "[fd00::1]:5000"},
+		{"::ffff:10.0.0.1", ":5000", "[::ffff:10.0.0.1]:5000"},
+		{"cache1", ":5000", "cache1:5000"},
+		{"10.0.0.1", "5000", "10.0.0.1:5000"},
+	}
+
+	for _, data := range table {
+		if got := joinHostPort(data.addr, data.port); got != data.want {
+			t.Errorf("%s %s: expected %s, got %s", data.addr, data.port, data.want, got)
+		}
+	}
+
+	if got := buildURL("https", "fd00::1", ":5000"); got != "https://[fd00::1]:5000" {
+		t.Errorf("unexpected ipv6 url: %s", got)
+	}
+}
+
+func TestIPFamilyValidate(t *testing.T) {
+	for _, f := range []IPFamily{IPFamilyDefault, IPFamilyIPv4, IPFamilyIPv6,
+		IPFamilyPreferIPv4, IPFamilyPreferIPv6} {
+		if err := f.validate(); err != nil {
+			t.Errorf("%q: %v", f, err)
+		}
+	}
+	if err := IPFamily("ipv4").validate(); err == nil {
+		t.Errorf("expected error for unknown family")
+	}
+}
@@ -3,64 +3,143 @@ package kubegroup
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"maps"
 	"net"
+	"net/netip"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
 	"time"
 
 	"github.com/groupcache/groupcache-go/v3/transport/peer"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/udhos/aws-emf/emf"
 	"github.com/udhos/cloudwatchlog/cwlog"
-	"github.com/udhos/kubepodinformer/podinformer"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
+	listerscorev1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 )
 
 // FindMyURL returns my URL for groupcache pool.
 // groupcachePort example: ":5000".
 // Sample resulting URL: "http://10.0.0.1:5000"
 func FindMyURL(groupcachePort string) (string, error) {
+	return FindMyURLWithScheme("http", groupcachePort)
+}
+
+// FindMyURLWithScheme returns my URL for groupcache pool, with scheme
+// "http" or "https". See Options.Scheme.
+// Sample resulting URL: "https://10.0.0.1:5000"
+func FindMyURLWithScheme(scheme, groupcachePort string) (string, error) {
 	addr, errAddr := findMyAddr()
 	if errAddr != nil {
 		return "", errAddr
 	}
-	url := buildURL(addr, groupcachePort)
+	url := buildURL(scheme, addr, groupcachePort)
 	return url, nil
 }
 
+// FindMyHostPort returns my host:port for groupcache3 daemon.
+// groupcachePort example: ":5000".
+// Sample resulting host:port: "10.0.0.1:5000", "[fd00::1]:5000"
+func FindMyHostPort(groupcachePort string) (string, error) {
+	addr, errAddr := findMyAddr()
+	if errAddr != nil {
+		return "", errAddr
+	}
+	return joinHostPort(addr, groupcachePort), nil
+}
+
 // FindMyAddress returns my address.
+// It tries env var POD_IP, then hostname lookup.
+// See DetectSelfAddress for more detection methods.
 func FindMyAddress() (string, error) {
 	return findMyAddr()
 }
 
 func findMyAddr() (string, error) {
-	host, errHost := os.Hostname()
-	if errHost != nil {
-		return "", errHost
+	addr, _, err := DetectSelfAddress(context.Background(), Options{})
+	return addr, err
+}
+
+// findNamespaces returns namespaces watched by builtin discovery.
+// NamespaceAll ("") means all namespaces.
+func findNamespaces(options Options) ([]string, error) {
+	switch {
+	case options.Discoverer != nil:
+		return nil, nil // namespace is only required by builtin discovery
+	case options.AllNamespaces:
+		return []string{metav1.NamespaceAll}, nil
+	case len(options.Namespaces) > 0:
+		return slices.Compact(slices.Sorted(slices.Values(options.Namespaces))), nil
 	}
-	addrs, errAddr := net.LookupHost(host)
-	if errAddr != nil {
-		return "", errAddr
+	ns, err := findMyNamespace(options)
+	if err != nil {
+		return nil, err
 	}
-	if len(addrs) < 1 {
-		return "", fmt.Errorf("findMyAddr: hostname '%s': no addr found", host)
+	return []string{ns}, nil
+}
+
+// newDiscoverer creates builtin discoverer for namespace.
+func (g *Group) newDiscoverer(namespace string) Discoverer {
+	if g.options.ServiceName != "" {
+		return &endpointSliceDiscoverer{
+			group:     g,
+			namespace: namespace,
+		}
 	}
-	addr := addrs[0]
-	if len(addrs) > 1 {
-		return addr, fmt.Errorf("findMyAddr: hostname '%s': found multiple addresses: %v", host, addrs)
+	return &podDiscoverer{
+		group:     g,
+		namespace: namespace,
 	}
-	return addr, nil
 }
 
-func findMyNamespace() (string, error) {
-	buf, err := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace")
-	return string(buf), err
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// findMyNamespace looks up the current POD's namespace, trying in order:
+// Options.Namespace, env var POD_NAMESPACE, the service account file.
+func findMyNamespace(options Options) (string, error) {
+	if options.Namespace != "" {
+		return options.Namespace, nil
+	}
+	if options.ForceNamespaceDefault {
+		return "default", nil
+	}
+	if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+		return ns, nil
+	}
+	buf, err := os.ReadFile(serviceAccountNamespaceFile)
+	if err != nil {
+		return "", fmt.Errorf("%w: tried Options.Namespace, env var POD_NAMESPACE, file %s: %v",
+			ErrNoNamespace, serviceAccountNamespaceFile, err)
+	}
+	ns := strings.TrimSpace(string(buf))
+	if ns == "" {
+		return "", fmt.Errorf("%w: tried Options.Namespace, env var POD_NAMESPACE, file %s: empty file",
+			ErrNoNamespace, serviceAccountNamespaceFile)
+	}
+	return ns, nil
 }
 
-func buildURL(addr, groupcachePort string) string {
-	return "http://" + addr + groupcachePort
+func buildURL(scheme, addr, groupcachePort string) string {
+	return scheme + "://" + joinHostPort(addr, groupcachePort)
+}
+
+// joinHostPort joins addr and groupcachePort, like ":5000", bracketing
+// IPv6 addresses: "[fd00::1]:5000".
+func joinHostPort(addr, groupcachePort string) string {
+	return net.JoinHostPort(addr, strings.TrimPrefix(groupcachePort, ":"))
 }
 
 // PeerGroup is an interface to plug in a target for delivering peering
@@ -77,6 +156,17 @@ type PeerSet interface {
 	SetPeers(ctx context.Context, peers []peer.Info) error
 }
 
+const (
+	// DefaultExcludeAnnotation is the default for Options.ExcludeAnnotation.
+	DefaultExcludeAnnotation = "kubegroup.io/exclude"
+
+	// DefaultPortAnnotation is the default for Options.PortAnnotation.
+	DefaultPortAnnotation = "kubegroup.io/port"
+
+	// DefaultWeightAnnotation is the default for Options.WeightAnnotation.
+	DefaultWeightAnnotation = "kubegroup.io/weight"
+)
+
 // Options specifies options for UpdatePeers.
 type Options struct {
 	// Pool is an interface to plug in a target for delivering peering
@@ -92,15 +182,138 @@ type Options struct {
 	Peers PeerSet
 
 	// Client provides kubernetes client.
+	// Client is required, unless Discoverer is defined.
 	Client *kubernetes.Clientset
 
+	// Discoverer optionally replaces the builtin discovery of PODs or
+	// EndpointSlices with a custom source of peers.
+	// When Discoverer is defined, Client, LabelSelector and ServiceName
+	// are ignored.
+	Discoverer Discoverer
+
 	// GroupCachePort is the listening port used by groupcache peering http
 	// server. For instance, ":5000".
+	// GroupCachePort is the default port for peers whose port is not
+	// found by PortAnnotation or PortName.
 	GroupCachePort string
 
-	// LabelSelector is required. Example: "key1=value1,key2=value2"
+	// Scheme is the URL scheme for groupcache2 peers (Pool): "http" or
+	// "https". Default is "http". For HTTPS, see NewTLSTransport and
+	// FindMyURLWithScheme.
+	Scheme string
+
+	// PeerURLTemplate optionally defines the peer address delivered to
+	// groupcache, as a text/template executed with PeerURLData.
+	// For groupcache2 (Pool), it produces URLs, like
+	// "https://{{.Name}}.svc-headless.{{.Namespace}}.svc:{{.Port}}/_groupcache/".
+	// For groupcache3 (Peers), it produces host:port strings, like
+	// "{{.Name}}.svc-headless.{{.Namespace}}.svc:{{.Port}}".
+	// The pool's own URL must match the template output for the current
+	// POD. Default builds addresses from POD IP and port.
+	PeerURLTemplate string
+
+	// PortAnnotation is the POD annotation that optionally defines the
+	// peer's groupcache port, like "5000". Default is "kubegroup.io/port".
+	PortAnnotation string
+
+	// PortName optionally names the container port that defines the
+	// peer's groupcache port. Example: "groupcache".
+	PortName string
+
+	// LabelSelector is required, unless ServiceName or LabelSelectorKeys
+	// is defined.
+	// Example: "key1=value1,key2=value2"
 	LabelSelector string
 
+	// LabelSelectorKeys optionally builds LabelSelector from the labels
+	// of the current POD, retrieved from kubernetes API by name
+	// (env var POD_NAME, or hostname). Only the given label keys are
+	// used. Example: []string{"app", "app.kubernetes.io/instance"}.
+	// LabelSelectorKeys is used only when LabelSelector is undefined.
+	LabelSelectorKeys []string
+
+	// FieldSelector optionally restricts PODs watched as peers.
+	// FieldSelector is not used with ServiceName.
+	// Example: "spec.nodeName!=draining-node"
+	FieldSelector string
+
+	// SelfAddress optionally defines the current POD's address.
+	// See SelfAddressMethods.
+	SelfAddress string
+
+	// SelfAddressMethods optionally defines the ordered methods for
+	// detecting the current POD's address.
+	// Default is DefaultSelfAddressMethods.
+	SelfAddressMethods []SelfAddressMethod
+
+	// SelfAddressCIDRs restricts method SelfAddressInterface to local
+	// addresses within these CIDRs. Example: []string{"10.0.0.0/8"}.
+	SelfAddressCIDRs []string
+
+	// IPFamily optionally selects the address family used to reach
+	// dual-stack peers. Default is the peer's primary address.
+	IPFamily IPFamily
+
+	// Topology optionally restricts peering by zone, to avoid cross-zone
+	// fetches. Zones of POD peers are read from their nodes, requiring
+	// permissions to get/list/watch nodes. Default is TopologyNone.
+	Topology Topology
+
+	// TopologyMinPeers is the minimum number of ready peers, including
+	// the current POD, in the current zone for TopologyPreferSameZone
+	// to restrict peering to the zone. Default is 2.
+	TopologyMinPeers int
+
+	// Zone optionally forces the current POD's zone. By default, the
+	// zone is taken from the peer identified as the current POD.
+	Zone string
+
+	// ExcludeAnnotation is the POD annotation that, when set to "true",
+	// excludes the POD from peering. Default is "kubegroup.io/exclude".
+	ExcludeAnnotation string
+
+	// WeightAnnotation is the POD annotation that optionally defines the
+	// peer's weight, like "4". Bigger peers own proportionally more
+	// keys. Default is "kubegroup.io/weight".
+	WeightAnnotation string
+
+	// WeightMemoryUnit optionally derives peer weight from POD memory:
+	// the sum of container memory limits (falling back to requests)
+	// divided by WeightMemoryUnit, like resource.MustParse("1Gi").
+	// WeightAnnotation takes precedence.
+	WeightMemoryUnit resource.Quantity
+
+	// MaxWeight caps peer weight. Default is 10.
+	MaxWeight int
+
+	// VirtualPeers delivers weights to a Pool that does not implement
+	// WeightedPeerGroup, by replicating weight-1 virtual entries for each
+	// peer. Every peer must then serve its pool with VirtualPeerHandler.
+	// If VirtualPeers is false, such a Pool receives unweighted peers.
+	// Peers (groupcache3) receive weights only if it implements
+	// WeightedPeerSet.
+	//
+	// Costs: HTTPPool recognizes itself only by its exact URL, so keys
+	// owned by the current POD's virtual entries, (w-1)/w of its keys for
+	// weight w, are fetched over an HTTP request from the POD to itself.
+	// Prefer a Pool implementing WeightedPeerGroup when possible.
+	//
+	// Rollout: virtual entries are only served by peers running
+	// VirtualPeerHandler. Roll out VirtualPeerHandler to every peer first,
+	// with VirtualPeers disabled, then enable VirtualPeers in a second
+	// rollout. Disable in the reverse order.
+	VirtualPeers bool
+
+	// ServiceName optionally switches discovery from watching PODs to
+	// watching the discovery.k8s.io/v1 EndpointSlices of the named
+	// Service, in the current POD's namespace.
+	// When ServiceName is defined, LabelSelector is ignored.
+	ServiceName string
+
+	// ServicePortName optionally selects the EndpointSlice port, by name,
+	// used to reach peers. If undefined, GroupCachePort is used.
+	ServicePortName string
+
 	// Debug enables non-error logging. Errors are always logged.
 	Debug bool
 
@@ -143,10 +356,144 @@ type Options struct {
 	EmfCloudWatchLogsClient *cwlog.Log
 
 	// ForceNamespaceDefault is used only for testing.
+	//
+	// Deprecated: Use Namespace: "default".
 	ForceNamespaceDefault bool
 
+	// Namespace optionally sets the namespace to watch for peers.
+	// If undefined, the current POD's namespace is taken from
+	// env var POD_NAMESPACE, then from the service account file.
+	Namespace string
+
+	// Namespaces optionally lists namespaces to watch for peers,
+	// instead of the current POD's namespace. Peers found across
+	// namespaces are merged into a single peer list.
+	Namespaces []string
+
+	// AllNamespaces enables watching for peers in all namespaces.
+	// It requires cluster-wide permissions (ClusterRole).
+	AllNamespaces bool
+
 	// DebounceDelay is the delay for debouncing peer updates. Default is 2 seconds.
 	DebounceDelay time.Duration
+
+	// SetPeersTimeout limits the duration of each Peers.SetPeers call.
+	// Default is 10 seconds.
+	SetPeersTimeout time.Duration
+
+	// InformerRestartMinDelay is the initial delay before restarting
+	// an exited POD informer, or Discoverer. Default is 1 second.
+	InformerRestartMinDelay time.Duration
+
+	// InformerRestartMaxDelay caps the exponential backoff between
+	// POD informer restarts. Default is 1 minute.
+	InformerRestartMaxDelay time.Duration
+
+	// InformerMaxRestarts limits consecutive POD informer restarts.
+	// When exceeded, discovery stops with ErrInformerMaxRestarts.
+	// Zero means unlimited.
+	InformerMaxRestarts int
+
+	// OnInformerFailure is optionally called when discovery stops
+	// because InformerMaxRestarts was exceeded.
+	OnInformerFailure func(err error)
+
+	// DrainGracePeriod is the time Group.Drain waits, after removing
+	// the current POD from peering, before stopping discovery.
+	// Default is 5 seconds.
+	DrainGracePeriod time.Duration
+
+	// WaitInitialSync makes UpdatePeers block until the first peer
+	// list has been delivered to Pool or Peers. See Group.WaitForSync.
+	WaitInitialSync bool
+
+	// WaitInitialSyncTimeout optionally limits the wait for
+	// WaitInitialSync. Zero means wait until the context is done.
+	WaitInitialSyncTimeout time.Duration
+}
+
+// Validate checks options for UpdatePeers.
+// Errors wrap ErrNoPeerTarget, ErrNoClient, ErrInvalidPort,
+// ErrInvalidScheme, ErrInvalidPeerURLTemplate, ErrInvalidIPFamily,
+// ErrInvalidTopology, ErrInvalidMaxWeight, ErrInvalidSelfAddressCIDR,
+// ErrInvalidLabelSelector or ErrInvalidFieldSelector.
+func (o Options) Validate() error {
+	if o.Pool == nil && o.Peers == nil {
+		return ErrNoPeerTarget
+	}
+	if err := validatePort(o.GroupCachePort); err != nil {
+		return err
+	}
+	switch o.Scheme {
+	case "", "http", "https":
+	default:
+		return fmt.Errorf("%w: '%s'", ErrInvalidScheme, o.Scheme)
+	}
+	if o.PeerURLTemplate != "" {
+		if _, err := parsePeerURLTemplate(o.PeerURLTemplate); err != nil {
+			return err
+		}
+	}
+	if err := o.IPFamily.validate(); err != nil {
+		return err
+	}
+	if err := o.Topology.validate(); err != nil {
+		return err
+	}
+	if o.TopologyMinPeers < 0 {
+		return fmt.Errorf("%w: TopologyMinPeers: %d", ErrInvalidTopology,
+			o.TopologyMinPeers)
+	}
+	if o.MaxWeight < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidMaxWeight, o.MaxWeight)
+	}
+	for _, c := range o.SelfAddressCIDRs {
+		if _, err := netip.ParsePrefix(c); err != nil {
+			return fmt.Errorf("%w: '%s': %v", ErrInvalidSelfAddressCIDR, c, err)
+		}
+	}
+	if o.Discoverer != nil {
+		return nil // Client and LabelSelector are ignored
+	}
+	if _, err := fields.ParseSelector(o.FieldSelector); err != nil {
+		return fmt.Errorf("%w: '%s': %v", ErrInvalidFieldSelector,
+			o.FieldSelector, err)
+	}
+	if o.Client == nil {
+		return ErrNoClient
+	}
+	if o.ServiceName != "" {
+		return nil // LabelSelector is ignored
+	}
+	if o.LabelSelector == "" {
+		if len(o.LabelSelectorKeys) > 0 {
+			return nil // LabelSelector will be built from POD labels
+		}
+		return fmt.Errorf("%w: empty", ErrInvalidLabelSelector)
+	}
+	if _, err := labels.Parse(o.LabelSelector); err != nil {
+		return fmt.Errorf("%w: '%s': %v", ErrInvalidLabelSelector,
+			o.LabelSelector, err)
+	}
+	return nil
+}
+
+// validatePort checks that groupcachePort looks like ":5000".
+func validatePort(groupcachePort string) error {
+	p, found := strings.CutPrefix(groupcachePort, ":")
+	if !found {
+		return fmt.Errorf("%w: '%s': missing ':' prefix", ErrInvalidPort,
+			groupcachePort)
+	}
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		return fmt.Errorf("%w: '%s': %v", ErrInvalidPort, groupcachePort, err)
+	}
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("%w: '%s': out of range", ErrInvalidPort,
+			groupcachePort)
+	}
+	return nil
 }
 
 // DogstatsdClient is implemented by *statsd.Client.
@@ -164,10 +511,50 @@ type DogstatsdClient interface {
 
 // Group holds context for kubegroup.
 type Group struct {
-	options  Options
-	informer *podinformer.PodInformer
-	m        *metrics
-	myAddr   string
+	options    Options
+	discoverer Discoverer
+	m          *metrics
+	myAddr     string
+
+	peerURLTemplate *template.Template
+
+	myAddrMethod SelfAddressMethod
+	myPodName    string
+	myPodUID     string
+	myNamespace  string
+	selfMissing  bool
+
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+
+	synced   chan struct{}
+	syncOnce sync.Once
+
+	nodeOnce     sync.Once
+	nodes        listerscorev1.NodeLister // see nodeLister
+	nodeInformer cache.SharedIndexInformer
+
+	peersMutex sync.Mutex
+	peers      []PeerInfo
+
+	subMutex      sync.Mutex
+	subscriptions []*subscription
+
+	deliverMutex sync.Mutex
+	delivered    []PeerInfo // nil until first delivery
+	draining     atomic.Bool
+
+	statusMutex sync.Mutex
+	status      groupStatus
+
+	namespaces []string
+
+	warnedMutex sync.Mutex
+	warned      map[string]struct{} // see warnOnce
+
+	mutex sync.Mutex
+	err   error
 }
 
 func (g *Group) debugf(format string, v ...any) {
@@ -176,33 +563,117 @@ func (g *Group) debugf(format string, v ...any) {
 	}
 }
 
+func (g *Group) warnf(format string, v ...any) {
+	g.options.Logf("WARN kubegroup: "+format, v...)
+}
+
+// maxWarned bounds the keys remembered by warnOnce.
+const maxWarned = 1000
+
+// warnOnce logs a warning once per key, so that an invalid setting
+// reevaluated on every informer event does not flood the logs.
+func (g *Group) warnOnce(key, format string, v ...any) {
+	g.warnedMutex.Lock()
+	if len(g.warned) >= maxWarned {
+		g.warned = nil // forget, rather than grow forever
+	}
+	if g.warned == nil {
+		g.warned = map[string]struct{}{}
+	}
+	_, found := g.warned[key]
+	g.warned[key] = struct{}{}
+	g.warnedMutex.Unlock()
+
+	if !found {
+		g.warnf(format, v...)
+	}
+}
+
 func (g *Group) errorf(format string, v ...any) {
 	g.options.Logf("ERROR kubegroup: "+format, v...)
+	msg := fmt.Sprintf(format, v...)
+	g.updateStatus(func(s *groupStatus) {
+		s.LastError = msg
+		s.LastErrorTime = time.Now()
+	})
 }
 
 // Close terminates kubegroup goroutines to release resources.
-func (g *Group) Close() {
+// Close waits for the discovery goroutine to exit. It returns nil
+// if discovery was stopped by Close or by context cancellation,
+// otherwise it returns the error that stopped discovery.
+func (g *Group) Close() error {
 	g.debugf("Close called to release resources")
-	g.informer.Stop()
+	g.cancel(ErrClosed)
+	<-g.done
+	err := g.Err()
+	if errors.Is(err, ErrClosed) || errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+	return err
 }
 
-// UpdatePeers continuously updates groupcache peers.
-func UpdatePeers(options Options) (*Group, error) {
+// Done returns a channel that is closed when the discovery goroutine exits.
+func (g *Group) Done() <-chan struct{} {
+	return g.done
+}
 
-	//
-	// Required fields.
-	//
-	if options.Pool == nil && options.Peers == nil {
-		panic("Pool and Peers are both nil")
-	}
-	if options.Client == nil {
-		panic("Client is nil")
+// Err returns nil while discovery is running. After Done is closed,
+// Err reports why discovery exited: ErrClosed if Close was called,
+// the context cause if the context given to UpdatePeersContext was
+// canceled, or the error that stopped the informer.
+func (g *Group) Err() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.err
+}
+
+func (g *Group) setErr(err error) {
+	g.mutex.Lock()
+	g.err = err
+	g.mutex.Unlock()
+}
+
+// WaitForSync blocks until the first peer list has been delivered
+// to Pool or Peers. It returns ctx error if ctx is done first, or
+// an error wrapping ErrNotSynced if discovery exits before the
+// initial sync.
+func (g *Group) WaitForSync(ctx context.Context) error {
+	select {
+	case <-g.synced:
+		return nil
+	default:
 	}
-	if options.GroupCachePort == "" {
-		panic("GroupCachePort is empty")
+	select {
+	case <-g.synced:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-g.done:
+		select {
+		case <-g.synced:
+			return nil
+		default:
+		}
+		return fmt.Errorf("%w: %w", ErrNotSynced, g.Err())
 	}
-	if options.LabelSelector == "" {
-		panic("LabelSelector is empty")
+}
+
+// UpdatePeers continuously updates groupcache peers.
+// Invalid options are reported by errors from Options.Validate.
+// UpdatePeers is UpdatePeersContext with context.Background().
+func UpdatePeers(options Options) (*Group, error) {
+	return UpdatePeersContext(context.Background(), options)
+}
+
+// UpdatePeersContext continuously updates groupcache peers until
+// ctx is canceled or Group.Close is called.
+// ctx is also propagated into Peers.SetPeers calls.
+func UpdatePeersContext(ctx context.Context, options Options) (*Group, error) {
+
+	if err := options.Validate(); err != nil {
+		return nil, err
 	}
 
 	if !options.DogstatsdDisableTagHostname {
@@ -221,21 +692,75 @@ func UpdatePeers(options Options) (*Group, error) {
 		options.Logf = log.Printf
 	}
 
-	var namespace string
-	if options.ForceNamespaceDefault {
-		namespace = "default"
-	} else {
-		ns, errNs := findMyNamespace()
-		if errNs != nil {
-			return nil, errNs
+	if options.Scheme == "" {
+		options.Scheme = "http"
+	}
+
+	if options.PortAnnotation == "" {
+		options.PortAnnotation = DefaultPortAnnotation
+	}
+
+	if options.WeightAnnotation == "" {
+		options.WeightAnnotation = DefaultWeightAnnotation
+	}
+
+	if options.MaxWeight == 0 {
+		options.MaxWeight = 10
+	}
+
+	if options.TopologyMinPeers == 0 {
+		options.TopologyMinPeers = 2
+	}
+
+	if options.ExcludeAnnotation == "" {
+		options.ExcludeAnnotation = DefaultExcludeAnnotation
+	}
+
+	if options.SetPeersTimeout == 0 {
+		options.SetPeersTimeout = 10 * time.Second
+	}
+
+	if options.DrainGracePeriod == 0 {
+		options.DrainGracePeriod = 5 * time.Second
+	}
+
+	if options.InformerRestartMinDelay == 0 {
+		options.InformerRestartMinDelay = time.Second
+	}
+
+	if options.InformerRestartMaxDelay == 0 {
+		options.InformerRestartMaxDelay = time.Minute
+	}
+
+	if options.LabelSelector == "" && options.Discoverer == nil &&
+		options.ServiceName == "" {
+		pod, errPod := findMyPod(ctx, options)
+		if errPod != nil {
+			return nil, errPod
+		}
+		selector, errSel := selectorFromPod(pod, options.LabelSelectorKeys)
+		if errSel != nil {
+			return nil, errSel
 		}
-		namespace = ns
+		options.LabelSelector = selector
 	}
 
-	myAddr, errAddr := findMyAddr()
+	namespaces, errNs := findNamespaces(options)
+	if errNs != nil {
+		return nil, errNs
+	}
+
+	myPodName, _ := findMyPodName()            // optional: identifies self among peers
+	myNamespace, _ := findMyNamespace(options) // optional: identifies self among peers
+
+	myAddr, myAddrMethod, errAddr := DetectSelfAddress(ctx, options)
 	if errAddr != nil {
 		return nil, errAddr
 	}
+	if options.Debug {
+		options.Logf("DEBUG kubegroup: self address %s found by method %s",
+			myAddr, myAddrMethod)
+	}
 
 	//
 	// enable AWS CloudWatch EMF metrics
@@ -259,66 +784,208 @@ func UpdatePeers(options Options) (*Group, error) {
 		}
 	}
 
+	var peerURLTemplate *template.Template
+	if options.PeerURLTemplate != "" {
+		peerURLTemplate, _ = parsePeerURLTemplate(options.PeerURLTemplate) // checked by Validate
+	}
+
+	groupCtx, groupCancel := context.WithCancelCause(ctx)
+
 	group := &Group{
 		options: options,
 		m: newMetrics(options.MetricsNamespace,
 			options.MetricsRegisterer, options.DogstatsdClient,
 			options.DogstatsdExtraTags, emfMetric, emfDimensions,
 			options.EmfCloudWatchLogsClient),
-		myAddr: myAddr,
+		myAddr:          myAddr,
+		peerURLTemplate: peerURLTemplate,
+		myAddrMethod:    myAddrMethod,
+		myPodName:       myPodName,
+		myPodUID:        os.Getenv("POD_UID"),
+		myNamespace:     myNamespace,
+		namespaces:      namespaces,
+		ctx:             groupCtx,
+		cancel:          groupCancel,
+		done:            make(chan struct{}),
+		synced:          make(chan struct{}),
 	}
 
-	optionsInformer := podinformer.Options{
-		Client:        options.Client,
-		Namespace:     namespace,
-		LabelSelector: options.LabelSelector,
-		OnUpdate:      group.onUpdate,
-		DebugLog:      options.Debug,
-		DebounceDelay: options.DebounceDelay,
+	if options.Discoverer != nil {
+		group.discoverer = options.Discoverer
+	} else {
+		var discoverers []Discoverer
+		for _, ns := range namespaces {
+			discoverers = append(discoverers, group.newDiscoverer(ns))
+		}
+		if len(discoverers) == 1 {
+			group.discoverer = discoverers[0]
+		} else {
+			group.discoverer = &multiDiscoverer{discoverers: discoverers}
+		}
 	}
 
-	group.informer = podinformer.New(optionsInformer)
+	go group.supervise()
 
-	go func() {
-		errInformer := group.informer.Run()
-		group.errorf("informer exited, error: %v", errInformer)
-	}()
+	if options.WaitInitialSync {
+		waitCtx := ctx
+		if options.WaitInitialSyncTimeout > 0 {
+			var cancel context.CancelFunc
+			waitCtx, cancel = context.WithTimeout(ctx, options.WaitInitialSyncTimeout)
+			defer cancel()
+		}
+		if err := group.WaitForSync(waitCtx); err != nil {
+			group.Close()
+			return nil, err
+		}
+	}
 
 	return group, nil
 }
 
-func (g *Group) onUpdate(pods []podinformer.Pod) {
+func (g *Group) onUpdate(pods []PeerCandidate) {
 	const me = "onUpdate"
 
 	size := len(pods)
 	g.debugf("%s: %d", me, size)
 
+	now := time.Now()
+
+	g.updateStatus(func(s *groupStatus) {
+		s.Updates++
+		s.LastUpdate = now
+	})
+
+	infos := make([]PeerInfo, 0, size)
+	namespacePeers := map[string]int{}
+
+	for i, p := range pods {
+		info, found := g.peerInfo(p, now)
+		if !found {
+			g.debugf("%s: %d/%d: namespace=%s pod=%s ips=%v: no address for ip_family=%s",
+				me, i+1, size, p.Namespace, p.Name, p.IPs, g.options.IPFamily)
+			continue
+		}
+
+		g.debugf("%s: %d/%d: namespace=%s pod=%s ip=%s ready=%t address=%s is_self=%t",
+			me, i+1, size, info.Namespace, info.Name, info.IP, info.Ready,
+			info.Address, info.IsSelf)
+
+		if info.ExcludeReason != "" {
+			g.debugf("%s: %d/%d: namespace=%s pod=%s excluded: %s",
+				me, i+1, size, info.Namespace, info.Name, info.ExcludeReason)
+		}
+
+		infos = append(infos, info)
+		if info.Namespace != "" {
+			namespacePeers[info.Namespace]++
+		}
+	}
+
+	g.checkSelf(infos)
+
+	applied := g.deliver(infos)
+
+	prev := g.savePeers(infos)
+
+	if ev := diffPeers(prev, infos); !ev.empty() {
+		g.publish(ev)
+	}
+
+	g.m.update(size, namespacePeers, zonePeers(infos))
+
+	if applied {
+		g.syncOnce.Do(func() { close(g.synced) })
+	}
+}
+
+// checkSelf warns when the current POD is missing from peers.
+// Only onUpdate calls checkSelf, so selfMissing needs no locking.
+func (g *Group) checkSelf(infos []PeerInfo) {
+	missing := !slices.ContainsFunc(infos, func(p PeerInfo) bool { return p.IsSelf })
+	if missing && !g.selfMissing {
+		g.warnf("self not found among %d discovered peers: pod=%s uid=%s addr=%s",
+			len(infos), g.myPodName, g.myPodUID, g.myAddr)
+	}
+	g.selfMissing = missing
+	g.m.setSelfMissing(missing)
+}
+
+// deliverable reports whether peer p should be delivered.
+// groupcache3 (Peers) rejects peer lists without self, so self is
+// always delivered to Peers, even while not ready or draining;
+// otherwise a POD gating its readiness on WaitForSync would never
+// become ready, and every delivery while draining would fail.
+func (g *Group) deliverable(p PeerInfo, draining bool) bool {
+	if p.IsSelf {
+		if g.options.Peers != nil {
+			return true
+		}
+		if draining {
+			return false
+		}
+	}
+	return p.active()
+}
+
+// deliver sends ready peers to Peers or Pool, sorted by address.
+// Delivery is skipped when ready peers are unchanged since the last
+// delivery, to avoid needlessly rebuilding the groupcache hash ring.
+// deliver reports whether Peers or Pool holds the ready peers, either
+// by this delivery or by a previous one; it returns false if SetPeers
+// failed.
+func (g *Group) deliver(infos []PeerInfo) bool {
+	g.deliverMutex.Lock()
+	defer g.deliverMutex.Unlock()
+
+	// read draining under deliverMutex, so that an update racing with
+	// Drain cannot re-deliver self after Drain's delivery.
+	ready := make([]PeerInfo, 0, len(infos))
+	draining := g.draining.Load()
+	for _, p := range infos {
+		if g.deliverable(p, draining) {
+			ready = append(ready, p)
+		}
+	}
+	ready = g.filterTopology(ready, g.selfZone(infos))
+	slices.SortFunc(ready, func(a, b PeerInfo) int {
+		return strings.Compare(a.Address, b.Address)
+	})
+
+	if g.delivered != nil && slices.EqualFunc(ready, g.delivered,
+		func(a, b PeerInfo) bool {
+			return a.Address == b.Address && a.IsSelf == b.IsSelf &&
+				a.Weight == b.Weight
+		}) {
+		g.debugf("deliver: %d ready peers unchanged, skipping", len(ready))
+		g.m.suppress()
+		g.updateStatus(func(s *groupStatus) { s.Suppressed++ })
+		return true
+	}
+
 	if g.options.Peers != nil {
 
 		//
 		// groupcache3
 		//
 
-		peers := make([]peer.Info, 0, size)
-
-		for i, p := range pods {
-			hostPort := p.IP + g.options.GroupCachePort
-			isSelf := g.myAddr == p.IP
-
-			g.debugf("%s: %d/%d: namespace=%s pod=%s ip=%s ready=%t host_port=%s is_self=%t",
-				me, i+1, size, p.Namespace, p.Name, p.IP, p.Ready, hostPort, isSelf)
-
-			if p.Ready {
+		ctx, cancel := context.WithTimeout(g.ctx, g.options.SetPeersTimeout)
+		var err error
+		if ws, ok := g.options.Peers.(WeightedPeerSet); ok {
+			err = ws.SetWeightedPeers(ctx, weightedPeers(ready))
+		} else {
+			peers := make([]peer.Info, 0, len(ready))
+			for _, p := range ready {
 				peers = append(peers, peer.Info{
-					Address: hostPort,
-					IsSelf:  isSelf,
+					Address: p.Address,
+					IsSelf:  p.IsSelf,
 				})
 			}
+			err = g.options.Peers.SetPeers(ctx, peers)
 		}
-
-		err := g.options.Peers.SetPeers(context.TODO(), peers)
+		cancel()
 		if err != nil {
 			g.errorf("set peers: error: %v", err)
+			return false // retry on next update
 		}
 
 	} else {
@@ -327,21 +994,24 @@ func (g *Group) onUpdate(pods []podinformer.Pod) {
 		// groupcache2
 		//
 
-		peers := make([]string, 0, size)
-
-		for i, p := range pods {
-			g.debugf("%s: %d/%d: namespace=%s pod=%s ip=%s ready=%t",
-				me, i+1, size, p.Namespace, p.Name, p.IP, p.Ready)
-
-			if p.Ready {
-				peers = append(peers, buildURL(p.IP, g.options.GroupCachePort))
+		if wg, ok := g.options.Pool.(WeightedPeerGroup); ok {
+			wg.SetWeighted(weightedPeers(ready))
+		} else {
+			peers := make([]string, 0, len(ready))
+			for _, p := range ready {
+				if g.options.VirtualPeers {
+					peers = append(peers, virtualPeers(p.Address, p.Weight)...)
+				} else {
+					peers = append(peers, p.Address)
+				}
 			}
+			g.options.Pool.Set(peers...)
 		}
-
-		g.options.Pool.Set(peers...)
 	}
 
-	g.m.update(size)
+	g.delivered = ready
+	g.updateStatus(func(s *groupStatus) { s.Deliveries++ })
+	return true
 }
 
 // DogstatsdClientMock mocks the interface DogstatsdClient.
@@ -0,0 +1,278 @@
+package kubegroup
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/groupcache/groupcache-go/v3/transport/peer"
+	"k8s.io/client-go/kubernetes"
+)
+
+// fakePool records peers delivered to Pool.
+type fakePool struct {
+	calls [][]string
+}
+
+func (p *fakePool) Set(peers ...string) {
+	p.calls = append(p.calls, peers)
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() Options {
+		return Options{
+			Pool:           &fakePool{},
+			Client:         &kubernetes.Clientset{},
+			GroupCachePort: ":5000",
+			LabelSelector:  "app=miniapi",
+		}
+	}
+
+	discoverer, err := NewStaticDiscoverer("10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table := []struct {
+		name   string
+		change func(o *Options)
+		want   error
+	}{
+		{"valid", func(_ *Options) {}, nil},
+		{"no target", func(o *Options) { o.Pool = nil }, ErrNoPeerTarget},
+		{"port without colon", func(o *Options) { o.GroupCachePort = "5000" }, ErrInvalidPort},
+		{"port not a number", func(o *Options) { o.GroupCachePort = ":http" }, ErrInvalidPort},
+		{"port zero", func(o *Options) { o.GroupCachePort = ":0" }, ErrInvalidPort},
+		{"port too big", func(o *Options) { o.GroupCachePort = ":65536" }, ErrInvalidPort},
+		{"scheme", func(o *Options) { o.Scheme = "ftp" }, ErrInvalidScheme},
+		{"peer url template", func(o *Options) { o.PeerURLTemplate = "{{.IP" }, ErrInvalidPeerURLTemplate},
+		{"ip family", func(o *Options) { o.IPFamily = "IPv5" }, ErrInvalidIPFamily},
+		{"topology", func(o *Options) { o.Topology = "SameRack" }, ErrInvalidTopology},
+		{"topology min peers", func(o *Options) { o.TopologyMinPeers = -1 }, ErrInvalidTopology},
+		{"max weight", func(o *Options) { o.MaxWeight = -1 }, ErrInvalidMaxWeight},
+		{"self address cidr", func(o *Options) { o.SelfAddressCIDRs = []string{"10.0.0.0/33"} }, ErrInvalidSelfAddressCIDR},
+		{"no client", func(o *Options) { o.Client = nil }, ErrNoClient},
+		{"field selector", func(o *Options) { o.FieldSelector = "spec.nodeName" }, ErrInvalidFieldSelector},
+		{"empty label selector", func(o *Options) { o.LabelSelector = "" }, ErrInvalidLabelSelector},
+		{"bad label selector", func(o *Options) { o.LabelSelector = "app in (" }, ErrInvalidLabelSelector},
+		{"label selector from pod labels", func(o *Options) {
+			o.LabelSelector = ""
+			o.LabelSelectorKeys = []string{"app"}
+		}, nil},
+		{"service ignores label selector", func(o *Options) {
+			o.LabelSelector = ""
+			o.ServiceName = "miniapi"
+		}, nil},
+		{"discoverer ignores client", func(o *Options) {
+			o.Client = nil
+			o.LabelSelector = ""
+			o.Discoverer = discoverer
+		}, nil},
+	}
+
+	for _, data := range table {
+		t.Run(data.name, func(t *testing.T) {
+			o := valid()
+			data.change(&o)
+			err := o.Validate()
+			if data.want == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, data.want) {
+				t.Errorf("expected %v, got %v", data.want, err)
+			}
+		})
+	}
+}
+
+// fakePeerSet records peers delivered to Peers, failing while err is set.
+// Like groupcache3, it rejects peer lists without self.
+type fakePeerSet struct {
+	calls [][]peer.Info
+	err   error
+}
+
+func (p *fakePeerSet) SetPeers(_ context.Context, peers []peer.Info) error {
+	if p.err != nil {
+		return p.err
+	}
+	if !slices.ContainsFunc(peers, func(i peer.Info) bool { return i.IsSelf }) {
+		return errors.New("peer.Info{IsSelf: true} missing")
+	}
+	p.calls = append(p.calls, peers)
+	return nil
+}
+
+func newTestGroup(t *testing.T, options Options) *Group {
+	t.Helper()
+	options.Logf = t.Logf
+	options.Debug = true
+	options.SetPeersTimeout = time.Second
+	options.MaxWeight = 10
+	ctx, cancel := context.WithCancelCause(context.Background())
+	t.Cleanup(func() { cancel(ErrClosed) })
+	return &Group{
+		options:   options,
+		m:         newMetrics("", nil, nil, nil, nil, nil, nil),
+		myAddr:    "10.0.0.1",
+		myPodName: "self",
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		synced:    make(chan struct{}),
+	}
+}
+
+func TestDeliverSuppressesUnchanged(t *testing.T) {
+	pool := &fakePool{}
+	g := newTestGroup(t, Options{Pool: pool})
+
+	peers := []PeerInfo{
+		{Name: "b", Address: "http://10.0.0.2:5000", Ready: true, Weight: 1},
+		{Name: "a", Address: "http://10.0.0.1:5000", Ready: true, IsSelf: true, Weight: 1},
+		{Name: "c", Address: "http://10.0.0.3:5000", Ready: false, Weight: 1},
+	}
+
+	if !g.deliver(peers) {
+		t.Fatal("first delivery failed")
+	}
+
+	// same ready set in another order, unrelated change in unready peer
+	changed := []PeerInfo{peers[1], peers[0], {Name: "c", Address: "http://10.0.0.4:5000", Weight: 1}}
+	if !g.deliver(changed) {
+		t.Fatal("suppressed delivery must report success")
+	}
+
+	if len(pool.calls) != 1 {
+		t.Fatalf("expected 1 Set call, got %d", len(pool.calls))
+	}
+	want := []string{"http://10.0.0.1:5000", "http://10.0.0.2:5000"}
+	if !slices.Equal(pool.calls[0], want) {
+		t.Errorf("expected sorted ready peers %v, got %v", want, pool.calls[0])
+	}
+	if s := g.getStatus(); s.Deliveries != 1 || s.Suppressed != 1 {
+		t.Errorf("expected 1 delivery and 1 suppressed, got %+v", s)
+	}
+
+	// readiness change is delivered
+	peers[2].Ready = true
+	g.deliver(peers)
+	if len(pool.calls) != 2 || len(pool.calls[1]) != 3 {
+		t.Errorf("expected delivery of 3 peers, got %v", pool.calls)
+	}
+}
+
+func TestDeliverRetriesFailedSetPeers(t *testing.T) {
+	set := &fakePeerSet{err: errors.New("set peers failure")}
+	g := newTestGroup(t, Options{Peers: set})
+
+	peers := []PeerInfo{{Name: "a", Address: "10.0.0.1:5000", Ready: true, IsSelf: true, Weight: 1}}
+
+	if g.deliver(peers) {
+		t.Fatal("failed delivery must be reported")
+	}
+
+	set.err = nil
+
+	if !g.deliver(peers) {
+		t.Fatal("retried delivery failed")
+	}
+	if len(set.calls) != 1 {
+		t.Errorf("failed delivery must not suppress retry, got %d calls", len(set.calls))
+	}
+}
+
+func TestSyncWithSelfNotReady(t *testing.T) {
+	set := &fakePeerSet{}
+	g := newTestGroup(t, Options{Peers: set, GroupCachePort: ":5000"})
+
+	g.onUpdate([]PeerCandidate{
+		{Name: "self", IP: "10.0.0.1", Ready: false},
+		{Name: "other", IP: "10.0.0.2", Ready: true},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := g.WaitForSync(ctx); err != nil {
+		t.Fatalf("WaitForSync: %v", err)
+	}
+
+	if len(set.calls) != 1 || len(set.calls[0]) != 2 {
+		t.Fatalf("expected self and ready peer delivered, got %v", set.calls)
+	}
+}
+
+// blockingDiscoverer reports nothing and runs until ctx is done.
+type blockingDiscoverer struct{}
+
+func (blockingDiscoverer) Run(ctx context.Context, _ func(peers []PeerCandidate)) error {
+	<-ctx.Done()
+	return nil
+}
+
+func startTestGroup(t *testing.T, options Options) *Group {
+	t.Helper()
+	options.DrainGracePeriod = time.Millisecond
+	g := newTestGroup(t, options)
+	g.discoverer = blockingDiscoverer{}
+	go g.supervise()
+	return g
+}
+
+var drainCandidates = []PeerCandidate{
+	{Name: "self", IP: "10.0.0.1", Ready: true},
+	{Name: "other", IP: "10.0.0.2", Ready: true},
+}
+
+func TestDrainPool(t *testing.T) {
+	pool := &fakePool{}
+	g := startTestGroup(t, Options{Pool: pool, GroupCachePort: ":5000", Scheme: "http"})
+
+	g.onUpdate(drainCandidates)
+
+	if err := g.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+
+	if len(pool.calls) != 2 {
+		t.Fatalf("expected 2 Set calls, got %v", pool.calls)
+	}
+	if want := []string{"http://10.0.0.2:5000"}; !slices.Equal(pool.calls[1], want) {
+		t.Errorf("expected self removed %v, got %v", want, pool.calls[1])
+	}
+
+	select {
+	case <-g.Done():
+	default:
+		t.Errorf("group must be closed after Drain")
+	}
+}
+
+func TestDrainPeers(t *testing.T) {
+	set := &fakePeerSet{}
+	g := startTestGroup(t, Options{Peers: set, GroupCachePort: ":5000"})
+
+	g.onUpdate(drainCandidates)
+
+	if err := g.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+
+	if len(set.calls) != 1 {
+		t.Errorf("expected no re-delivery while draining, got %v", set.calls)
+	}
+	if s := g.getStatus(); s.LastError != "" {
+		t.Errorf("unexpected error: %s", s.LastError)
+	}
+
+	// updates while draining keep self
+	g.draining.Store(true)
+	if !g.deliver([]PeerInfo{{Name: "self", Address: "10.0.0.1:5000", IsSelf: true, Ready: true}}) {
+		t.Errorf("delivery while draining failed")
+	}
+}
@@ -3,6 +3,8 @@ package kubegroup
 import (
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -12,8 +14,13 @@ import (
 
 type metrics struct {
 	// prometheus
-	peers  prometheus.Gauge
-	events prometheus.Counter
+	peers            prometheus.Gauge
+	namespacePeers   *prometheus.GaugeVec
+	zonePeers        *prometheus.GaugeVec
+	events           prometheus.Counter
+	informerRestarts prometheus.Counter
+	suppressed       prometheus.Counter
+	selfMissing      prometheus.Gauge
 
 	// dogstatsd
 	dogstatsdClient DogstatsdClient
@@ -28,11 +35,16 @@ type metrics struct {
 }
 
 var (
-	metricEvents = emf.MetricDefinition{Name: "events", Unit: "Count"}
-	metricPeers  = emf.MetricDefinition{Name: "peers", Unit: "Count"}
+	metricEvents           = emf.MetricDefinition{Name: "events", Unit: "Count"}
+	metricPeers            = emf.MetricDefinition{Name: "peers", Unit: "Count"}
+	metricNamespacePeers   = emf.MetricDefinition{Name: "namespace_peers", Unit: "Count"}
+	metricZonePeers        = emf.MetricDefinition{Name: "zone_peers", Unit: "Count"}
+	metricInformerRestarts = emf.MetricDefinition{Name: "informer_restarts", Unit: "Count"}
+	metricSuppressed       = emf.MetricDefinition{Name: "updates_suppressed", Unit: "Count"}
+	metricSelfMissing      = emf.MetricDefinition{Name: "self_missing", Unit: "Count"}
 )
 
-func (m *metrics) update(peers int) {
+func (m *metrics) update(peers int, namespacePeers, zonePeers map[string]int) {
 	if m.events != nil {
 		m.events.Inc()
 	}
@@ -50,21 +62,115 @@ func (m *metrics) update(peers int) {
 	}
 
 	if m.emfMetric != nil {
-
 		m.emfMetric.Record(m.emfNamespace, metricEvents, m.emfDimensions, 1)
 		m.emfMetric.Record(m.emfNamespace, metricPeers, m.emfDimensions, peers)
+	}
+
+	m.setVec(m.namespacePeers, metricNamespacePeers, "namespace", namespacePeers)
+	m.setVec(m.zonePeers, metricZonePeers, "zone", zonePeers)
 
-		if m.cwlogClient == nil {
-			// send metrics to stdout
-			m.emfMetric.Println()
-		} else {
-			// send metrics to cloudwatch logs
-			events := m.emfMetric.CloudWatchLogEvents()
-			if err := m.cwlogClient.PutLogEvents(events); err != nil {
-				slog.Error(fmt.Sprintf("kubegroup metrics.update() error: %v", err))
+	if m.emfMetric != nil {
+		m.emfSend("update")
+	}
+}
+
+// setVec replaces the values of a gauge labeled by label across all
+// enabled metrics backends. Recorded emf metrics are not sent.
+func (m *metrics) setVec(gauge *prometheus.GaugeVec, def emf.MetricDefinition,
+	label string, values map[string]int) {
+	if gauge != nil {
+		gauge.Reset()
+		for v, n := range values {
+			gauge.WithLabelValues(v).Set(float64(n))
+		}
+	}
+
+	if m.dogstatsdClient != nil {
+		for v, n := range values {
+			tags := append(slices.Clone(m.tags), label+":"+v)
+			if err := m.dogstatsdClient.Gauge(def.Name, float64(n), tags, m.sampleRate); err != nil {
+				slog.Error(fmt.Sprintf("exportGauge: error: %v", err))
 			}
 		}
 	}
+
+	if m.emfMetric != nil {
+		for v, n := range values {
+			dimensions := maps.Clone(m.emfDimensions)
+			dimensions[label] = v
+			m.emfMetric.Record(m.emfNamespace, def, dimensions, n)
+		}
+	}
+}
+
+func (m *metrics) restart() {
+	m.inc(m.informerRestarts, metricInformerRestarts, "restart")
+}
+
+func (m *metrics) suppress() {
+	m.inc(m.suppressed, metricSuppressed, "suppress")
+}
+
+func (m *metrics) setSelfMissing(missing bool) {
+	var value int
+	if missing {
+		value = 1
+	}
+	m.set(m.selfMissing, metricSelfMissing, value, "setSelfMissing")
+}
+
+// set sets a gauge across all enabled metrics backends.
+// The dogstatsd metric name is taken from the emf definition.
+func (m *metrics) set(gauge prometheus.Gauge, def emf.MetricDefinition,
+	value int, caller string) {
+	if gauge != nil {
+		gauge.Set(float64(value))
+	}
+
+	if m.dogstatsdClient != nil {
+		if err := m.dogstatsdClient.Gauge(def.Name, float64(value), m.tags, m.sampleRate); err != nil {
+			slog.Error(fmt.Sprintf("exportGauge: error: %v", err))
+		}
+	}
+
+	if m.emfMetric != nil {
+		m.emfMetric.Record(m.emfNamespace, def, m.emfDimensions, value)
+		m.emfSend(caller)
+	}
+}
+
+// inc increments a counter across all enabled metrics backends.
+// The dogstatsd metric name is taken from the emf definition.
+func (m *metrics) inc(counter prometheus.Counter, def emf.MetricDefinition,
+	caller string) {
+	if counter != nil {
+		counter.Inc()
+	}
+
+	if m.dogstatsdClient != nil {
+		if err := m.dogstatsdClient.Count(def.Name, 1, m.tags, m.sampleRate); err != nil {
+			slog.Error(fmt.Sprintf("exportCount: error: %v", err))
+		}
+	}
+
+	if m.emfMetric != nil {
+		m.emfMetric.Record(m.emfNamespace, def, m.emfDimensions, 1)
+		m.emfSend(caller)
+	}
+}
+
+// emfSend flushes recorded emf metrics.
+func (m *metrics) emfSend(caller string) {
+	if m.cwlogClient == nil {
+		// send metrics to stdout
+		m.emfMetric.Println()
+		return
+	}
+	// send metrics to cloudwatch logs
+	events := m.emfMetric.CloudWatchLogEvents()
+	if err := m.cwlogClient.PutLogEvents(events); err != nil {
+		slog.Error(fmt.Sprintf("kubegroup metrics.%s() error: %v", caller, err))
+	}
 }
 
 func newMetrics(namespace string, registerer prometheus.Registerer,
@@ -101,6 +207,28 @@ func newMetrics(namespace string, registerer prometheus.Registerer,
 		},
 	)
 
+	m.namespacePeers = newGaugeVec(
+		registerer,
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "namespace_peers",
+			Help:      "Number of peer PODs discovered per namespace.",
+		},
+		[]string{"namespace"},
+	)
+
+	m.zonePeers = newGaugeVec(
+		registerer,
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "zone_peers",
+			Help:      "Number of peer PODs discovered per zone.",
+		},
+		[]string{"zone"},
+	)
+
 	m.events = newCounter(
 		registerer,
 		prometheus.CounterOpts{
@@ -111,6 +239,36 @@ func newMetrics(namespace string, registerer prometheus.Registerer,
 		},
 	)
 
+	m.informerRestarts = newCounter(
+		registerer,
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "informer_restarts",
+			Help:      "Number of times the POD informer was restarted.",
+		},
+	)
+
+	m.selfMissing = newGauge(
+		registerer,
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "self_missing",
+			Help:      "1 if the current POD was not found among discovered peers, 0 otherwise.",
+		},
+	)
+
+	m.suppressed = newCounter(
+		registerer,
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "updates_suppressed",
+			Help:      "Number of peer updates skipped because ready peers were unchanged.",
+		},
+	)
+
 	return m
 }
 
@@ -119,6 +277,11 @@ func newGauge(registerer prometheus.Registerer,
 	return promauto.With(registerer).NewGauge(opts)
 }
 
+func newGaugeVec(registerer prometheus.Registerer,
+	opts prometheus.GaugeOpts, labelNames []string) *prometheus.GaugeVec {
+	return promauto.With(registerer).NewGaugeVec(opts, labelNames)
+}
+
 func newCounter(registerer prometheus.Registerer,
 	opts prometheus.CounterOpts) prometheus.Counter {
 	return promauto.With(registerer).NewCounter(opts)
@@ -0,0 +1,161 @@
+package kubegroup
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PeerInfo describes a peer POD discovered by kubegroup.
+type PeerInfo struct {
+	// Name is the POD name.
+	Name string `json:"name"`
+
+	// Namespace is the POD namespace.
+	Namespace string `json:"namespace"`
+
+	// IP is the POD IP address.
+	IP string `json:"ip"`
+
+	// Address is the peer address delivered to groupcache:
+	// host:port for groupcache3 (Peers), URL for groupcache2 (Pool).
+	Address string `json:"address"`
+
+	// Ready reports whether the POD is ready.
+	// Only ready PODs that are not excluded are delivered as peers.
+	Ready bool `json:"ready"`
+
+	// IsSelf reports whether the peer is the current POD.
+	IsSelf bool `json:"is_self"`
+
+	// Node is the name of the POD's node, if known.
+	Node string `json:"node,omitempty"`
+
+	// Zone is the POD's zone, if known.
+	Zone string `json:"zone,omitempty"`
+
+	// Weight is the peer weight. See Options.WeightAnnotation.
+	Weight int `json:"weight"`
+
+	// ExcludeReason, if defined, tells why the POD is excluded
+	// from peering.
+	ExcludeReason string `json:"exclude_reason,omitempty"`
+
+	// UpdatedAt is the time of the update that reported the peer.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Peers returns the PODs reported by the last peering update,
+// including PODs that are not ready.
+func (g *Group) Peers() []PeerInfo {
+	g.peersMutex.Lock()
+	defer g.peersMutex.Unlock()
+	return slices.Clone(g.peers)
+}
+
+// savePeers stores infos as current peers and returns previous peers.
+func (g *Group) savePeers(infos []PeerInfo) []PeerInfo {
+	g.peersMutex.Lock()
+	defer g.peersMutex.Unlock()
+	prev := g.peers
+	g.peers = infos
+	return prev
+}
+
+// peerInfo builds PeerInfo for candidate. It returns false if the
+// candidate has no address of the family required by IPFamily, or if
+// PeerURLTemplate fails for the candidate.
+func (g *Group) peerInfo(p PeerCandidate, now time.Time) (PeerInfo, bool) {
+	ips := p.IPs
+	if len(ips) == 0 {
+		ips = []string{p.IP}
+	}
+	ip := selectIP(ips, g.options.IPFamily)
+	if ip == "" {
+		return PeerInfo{}, false
+	}
+	port := p.Port
+	if port == "" {
+		port = g.options.GroupCachePort
+	}
+	addr, err := g.peerAddress(p, ip, port)
+	if err != nil {
+		g.errorf("peer url template: pod=%s/%s: %v", p.Namespace, p.Name, err)
+		return PeerInfo{}, false
+	}
+	return PeerInfo{
+		Name:          p.Name,
+		Namespace:     p.Namespace,
+		IP:            ip,
+		Address:       addr,
+		Ready:         p.Ready,
+		IsSelf:        g.isSelf(p, ips),
+		Node:          p.Node,
+		Zone:          p.Zone,
+		Weight:        g.peerWeight(p),
+		ExcludeReason: g.excludeReason(p),
+		UpdatedAt:     now,
+	}, true
+}
+
+// isSelf identifies the current POD by UID, when known, otherwise
+// by POD name, address or host name.
+func (g *Group) isSelf(p PeerCandidate, ips []string) bool {
+	if g.myPodUID != "" && p.UID != "" {
+		return p.UID == g.myPodUID
+	}
+	if p.Name != "" && p.Name == g.myPodName &&
+		(p.Namespace == "" || g.myNamespace == "" || p.Namespace == g.myNamespace) {
+		return true
+	}
+	if slices.Contains(ips, g.myAddr) {
+		return true
+	}
+	return g.isSelfHost(p)
+}
+
+// isSelfHost identifies the current POD among peers given by host name,
+// like static peer "cache1:5000": the host must match the hostname or
+// resolve to the current address, and the port must be GroupCachePort.
+func (g *Group) isSelfHost(p PeerCandidate) bool {
+	host := p.IP
+	if host == "" {
+		return false
+	}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return false // not a host name
+	}
+	if p.Port != "" && p.Port != g.options.GroupCachePort {
+		return false
+	}
+	if strings.EqualFold(host, g.myPodName) {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(g.ctx, 2*time.Second)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return false
+	}
+	return slices.Contains(addrs, g.myAddr)
+}
+
+// excludeReason returns why candidate is excluded from peering,
+// or empty string if candidate is not excluded.
+func (g *Group) excludeReason(p PeerCandidate) string {
+	if p.ExcludeReason != "" {
+		return p.ExcludeReason
+	}
+	if p.Annotations[g.options.ExcludeAnnotation] == "true" {
+		return "annotation " + g.options.ExcludeAnnotation + "=true"
+	}
+	return ""
+}
+
+// active reports whether the peer should be delivered.
+func (p PeerInfo) active() bool {
+	return p.Ready && p.ExcludeReason == ""
+}
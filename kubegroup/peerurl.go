@@ -0,0 +1,74 @@
+package kubegroup
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// PeerURLData is the data available to Options.PeerURLTemplate.
+type PeerURLData struct {
+	// Scheme is Options.Scheme, like "http".
+	Scheme string
+
+	// IP is the peer IP address selected by Options.IPFamily.
+	IP string
+
+	// Host is IP, bracketed if IPv6, like "[fd00::1]".
+	Host string
+
+	// Port is the peer port number, like "5000".
+	Port string
+
+	// Name is the POD name.
+	Name string
+
+	// Namespace is the POD namespace.
+	Namespace string
+
+	// Labels holds the POD labels, if known.
+	Labels map[string]string
+
+	// Annotations holds the POD annotations, if known.
+	Annotations map[string]string
+}
+
+func parsePeerURLTemplate(text string) (*template.Template, error) {
+	t, err := template.New("peer").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPeerURLTemplate, err)
+	}
+	return t, nil
+}
+
+// peerAddress builds the peer address delivered to groupcache.
+func (g *Group) peerAddress(p PeerCandidate, ip, port string) (string, error) {
+	if g.peerURLTemplate == nil {
+		if g.options.Peers != nil {
+			return joinHostPort(ip, port), nil // groupcache3
+		}
+		return buildURL(g.options.Scheme, ip, port), nil // groupcache2
+	}
+
+	host := ip
+	if strings.Contains(ip, ":") {
+		host = "[" + ip + "]"
+	}
+
+	data := PeerURLData{
+		Scheme:      g.options.Scheme,
+		IP:          ip,
+		Host:        host,
+		Port:        strings.TrimPrefix(port, ":"),
+		Name:        p.Name,
+		Namespace:   p.Namespace,
+		Labels:      p.Labels,
+		Annotations: p.Annotations,
+	}
+
+	var b strings.Builder
+	if err := g.peerURLTemplate.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,215 @@
+package kubegroup
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	listerscorev1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// podDiscoverer watches PODs matching LabelSelector and FieldSelector.
+// It is the default Discoverer.
+type podDiscoverer struct {
+	group     *Group
+	namespace string
+}
+
+// Run implements Discoverer.
+func (d *podDiscoverer) Run(ctx context.Context, onUpdate func(peers []PeerCandidate)) error {
+	g := d.group
+
+	factory := informers.NewSharedInformerFactoryWithOptions(g.options.Client, 0,
+		informers.WithNamespace(d.namespace),
+		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+			o.LabelSelector = g.options.LabelSelector
+			o.FieldSelector = g.options.FieldSelector
+		}))
+
+	pods := factory.Core().V1().Pods()
+
+	var nodes listerscorev1.NodeLister
+	var related []cache.SharedIndexInformer
+	if g.options.Topology != TopologyNone {
+		nodes = g.nodeLister()
+		related = append(related, g.nodeInformer) // node zones
+	}
+
+	name := fmt.Sprintf("pod informer: namespace=%s label_selector=%s field_selector=%s",
+		d.namespace, g.options.LabelSelector, g.options.FieldSelector)
+
+	return g.watchInformer(ctx, name, factory, pods.Informer(),
+		func() ([]PeerCandidate, error) {
+			return d.list(pods.Lister(), nodes)
+		}, onUpdate, related...)
+}
+
+// nodeSyncTimeout limits the wait for the node informer initial sync.
+const nodeSyncTimeout = 30 * time.Second
+
+// nodeLister returns the lister of the node informer shared by all POD
+// discoverers, starting the informer on first call. The informer runs
+// until the group is closed. If nodes cannot be synced within
+// nodeSyncTimeout, for instance for lack of permissions, the error is
+// reported and zones remain unknown until the informer syncs. Node
+// changes trigger a new POD list, see watchInformer.
+func (g *Group) nodeLister() listerscorev1.NodeLister {
+	g.nodeOnce.Do(func() {
+		factory := informers.NewSharedInformerFactory(g.options.Client, 0)
+		nodes := factory.Core().V1().Nodes()
+		informer := nodes.Informer() // register informer before start
+		g.nodes = nodes.Lister()
+		g.nodeInformer = informer
+
+		factory.Start(g.ctx.Done())
+		context.AfterFunc(g.ctx, factory.Shutdown)
+
+		ctx, cancel := context.WithTimeout(g.ctx, nodeSyncTimeout)
+		defer cancel()
+
+		if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+			if g.ctx.Err() == nil {
+				g.errorf("node informer: not synced after %v, zones unknown: check permissions to list/watch nodes",
+					nodeSyncTimeout)
+			}
+			return
+		}
+
+		g.debugf("node informer: synced")
+	})
+	return g.nodes
+}
+
+// nodeZone returns the zone label of node, if known.
+func nodeZone(nodes listerscorev1.NodeLister, node string) string {
+	if nodes == nil || node == "" {
+		return ""
+	}
+	n, err := nodes.Get(node)
+	if err != nil {
+		return ""
+	}
+	return n.Labels[corev1.LabelTopologyZone]
+}
+
+func (d *podDiscoverer) list(lister listerscorev1.PodLister,
+	nodes listerscorev1.NodeLister) ([]PeerCandidate, error) {
+	list, err := lister.Pods(d.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]PeerCandidate, 0, len(list))
+
+	for _, pod := range list {
+		if pod.Status.PodIP == "" {
+			continue // not scheduled yet
+		}
+		var excludeReason string
+		if pod.DeletionTimestamp != nil {
+			excludeReason = "terminating" // drop before readiness flips
+		}
+		candidates = append(candidates, PeerCandidate{
+			Name:          pod.Name,
+			Namespace:     pod.Namespace,
+			UID:           string(pod.UID),
+			IP:            pod.Status.PodIP,
+			IPs:           podIPs(pod),
+			Port:          d.podPort(pod),
+			Ready:         podReady(pod),
+			Node:          pod.Spec.NodeName,
+			Zone:          nodeZone(nodes, pod.Spec.NodeName),
+			Weight:        d.podWeight(pod),
+			Labels:        pod.Labels,
+			Annotations:   pod.Annotations,
+			ExcludeReason: excludeReason,
+		})
+	}
+
+	return candidates, nil
+}
+
+// podPort resolves the POD's groupcache port, like ":5000", trying in
+// order: annotation PortAnnotation, container port named PortName.
+// Empty port means GroupCachePort.
+func (d *podDiscoverer) podPort(pod *corev1.Pod) string {
+	g := d.group
+
+	if value, found := pod.Annotations[g.options.PortAnnotation]; found {
+		port := value
+		if !strings.HasPrefix(port, ":") {
+			port = ":" + port
+		}
+		if err := validatePort(port); err == nil {
+			return port
+		}
+		g.warnOnce("port/"+pod.Namespace+"/"+pod.Name+"="+value,
+			"pod %s/%s: annotation %s=%s: invalid port, ignoring",
+			pod.Namespace, pod.Name, g.options.PortAnnotation, value)
+	}
+
+	if name := g.options.PortName; name != "" {
+		for _, c := range pod.Spec.Containers {
+			for _, p := range c.Ports {
+				if p.Name == name {
+					return ":" + strconv.Itoa(int(p.ContainerPort))
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// podWeight derives POD weight from container memory limits, falling
+// back to requests, in units of WeightMemoryUnit. Zero means undefined.
+func (d *podDiscoverer) podWeight(pod *corev1.Pod) int {
+	unit := d.group.options.WeightMemoryUnit
+	if unit.Sign() <= 0 {
+		return 0
+	}
+
+	var total int64
+	for _, c := range pod.Spec.Containers {
+		mem := c.Resources.Limits.Memory()
+		if mem.IsZero() {
+			mem = c.Resources.Requests.Memory()
+		}
+		total += mem.Value()
+	}
+	if total == 0 {
+		return 0
+	}
+
+	return max(int(math.Round(float64(total)/float64(unit.Value()))), 1)
+}
+
+// podIPs returns all POD addresses, for dual-stack PODs.
+func podIPs(pod *corev1.Pod) []string {
+	ips := make([]string, 0, len(pod.Status.PodIPs))
+	for _, ip := range pod.Status.PodIPs {
+		ips = append(ips, ip.IP)
+	}
+	return ips
+}
+
+// podReady reports whether the POD is running with condition Ready.
+func podReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package kubegroup
+
+import (
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodPort(t *testing.T) {
+	g := newTestGroup(t, Options{
+		GroupCachePort: ":5000",
+		PortAnnotation: DefaultPortAnnotation,
+		PortName:       "groupcache",
+	})
+	var logs []string
+	g.options.Logf = func(format string, v ...any) {
+		logs = append(logs, fmt.Sprintf(format, v...))
+	}
+	d := &podDiscoverer{group: g}
+
+	newPod := func(annotation string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Ports: []corev1.ContainerPort{
+					{Name: "http", ContainerPort: 8080},
+					{Name: "groupcache", ContainerPort: 5001},
+				},
+			}}},
+		}
+		if annotation != "" {
+			pod.Annotations = map[string]string{DefaultPortAnnotation: annotation}
+		}
+		return pod
+	}
+
+	table := []struct {
+		annotation string
+		want       string
+	}{
+		{"", ":5001"},        // named container port
+		{"5002", ":5002"},    // annotation wins
+		{":5003", ":5003"},   // annotation with colon
+		{"invalid", ":5001"}, // invalid annotation is ignored
+	}
+	for _, data := range table {
+		if got := d.podPort(newPod(data.annotation)); got != data.want {
+			t.Errorf("annotation=%q: expected %s, got %s", data.annotation, data.want, got)
+		}
+	}
+
+	// invalid annotation is reported once, not as error
+	d.podPort(newPod("invalid"))
+	var warnings int
+	for _, l := range logs {
+		if l[:4] == "WARN" {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("expected 1 warning, got %d: %v", warnings, logs)
+	}
+	if s := g.getStatus(); s.LastError != "" {
+		t.Errorf("invalid annotation must not set LastError: %s", s.LastError)
+	}
+}
@@ -0,0 +1,167 @@
+package kubegroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+)
+
+// SelfAddressMethod is a method for detecting the current POD's address.
+type SelfAddressMethod string
+
+const (
+	// SelfAddressOption takes the address from Options.SelfAddress.
+	SelfAddressOption SelfAddressMethod = "option"
+
+	// SelfAddressEnv takes the address from env var POD_IP, usually
+	// defined with the downward API from status.podIP.
+	SelfAddressEnv SelfAddressMethod = "env"
+
+	// SelfAddressPod takes the address from status.podIP of the current
+	// POD, retrieved from kubernetes API. It requires Options.Client.
+	SelfAddressPod SelfAddressMethod = "pod"
+
+	// SelfAddressInterface takes the first address of local network
+	// interfaces matching Options.SelfAddressCIDRs.
+	SelfAddressInterface SelfAddressMethod = "interface"
+
+	// SelfAddressHostname resolves the hostname with DNS. It fails if
+	// the hostname resolves to multiple addresses.
+	SelfAddressHostname SelfAddressMethod = "hostname"
+)
+
+// DefaultSelfAddressMethods is the default for Options.SelfAddressMethods.
+// The downward API env var comes first, so that a POD_IP injected by
+// the manifest wins over a SelfAddress set in code.
+var DefaultSelfAddressMethods = []SelfAddressMethod{
+	SelfAddressEnv,
+	SelfAddressOption,
+	SelfAddressPod,
+	SelfAddressInterface,
+	SelfAddressHostname,
+}
+
+// errMethodSkipped reports a method not applicable to the given options.
+var errMethodSkipped = errors.New("not configured")
+
+// DetectSelfAddress finds the current POD's address by trying methods
+// in Options.SelfAddressMethods order. It returns the address and the
+// method that found it. If all methods fail, the error wraps
+// ErrNoSelfAddress and tells the failure of every method tried.
+func DetectSelfAddress(ctx context.Context, options Options) (string, SelfAddressMethod, error) {
+	methods := options.SelfAddressMethods
+	if len(methods) == 0 {
+		methods = DefaultSelfAddressMethods
+	}
+
+	var errs []error
+
+	for _, m := range methods {
+		addr, err := detectSelfAddress(ctx, options, m)
+		if err == nil {
+			return addr, m, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", m, err))
+	}
+
+	return "", "", fmt.Errorf("%w: %w", ErrNoSelfAddress, errors.Join(errs...))
+}
+
+func detectSelfAddress(ctx context.Context, options Options,
+	method SelfAddressMethod) (string, error) {
+
+	switch method {
+	case SelfAddressOption:
+		if options.SelfAddress == "" {
+			return "", errMethodSkipped
+		}
+		return options.SelfAddress, nil
+	case SelfAddressEnv:
+		if addr := os.Getenv("POD_IP"); addr != "" {
+			return addr, nil
+		}
+		return "", errors.New("env var POD_IP is empty")
+	case SelfAddressPod:
+		if options.Client == nil {
+			return "", errMethodSkipped
+		}
+		pod, err := findMyPod(ctx, options)
+		if err != nil {
+			return "", err
+		}
+		ips := podIPs(pod)
+		if len(ips) == 0 && pod.Status.PodIP != "" {
+			ips = []string{pod.Status.PodIP}
+		}
+		if addr := selectIP(ips, options.IPFamily); addr != "" {
+			return addr, nil
+		}
+		return "", fmt.Errorf("pod %s/%s: no address", pod.Namespace, pod.Name)
+	case SelfAddressInterface:
+		if len(options.SelfAddressCIDRs) == 0 {
+			return "", errMethodSkipped
+		}
+		return findInterfaceAddr(options.SelfAddressCIDRs)
+	case SelfAddressHostname:
+		return findMyAddrByHostname()
+	}
+	return "", fmt.Errorf("unknown method '%s'", method)
+}
+
+// findInterfaceAddr returns the first local interface address
+// contained in any of cidrs.
+func findInterfaceAddr(cidrs []string) (string, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, c := range cidrs {
+		p, err := netip.ParsePrefix(c)
+		if err != nil {
+			return "", err
+		}
+		prefixes = append(prefixes, p)
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "", err
+	}
+
+	for _, a := range addrs {
+		ipNet, ok := a.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		ip, ok := netip.AddrFromSlice(ipNet.IP)
+		if !ok {
+			continue
+		}
+		ip = ip.Unmap()
+		for _, p := range prefixes {
+			if p.Contains(ip) {
+				return ip.String(), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no interface address within %v", cidrs)
+}
+
+func findMyAddrByHostname() (string, error) {
+	host, errHost := os.Hostname()
+	if errHost != nil {
+		return "", errHost
+	}
+	addrs, errAddr := net.LookupHost(host)
+	if errAddr != nil {
+		return "", errAddr
+	}
+	if len(addrs) < 1 {
+		return "", fmt.Errorf("hostname '%s': no addr found", host)
+	}
+	if len(addrs) > 1 {
+		return "", fmt.Errorf("hostname '%s': found multiple addresses: %v", host, addrs)
+	}
+	return addrs[0], nil
+}
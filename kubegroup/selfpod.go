@@ -0,0 +1,53 @@
+package kubegroup
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// findMyPodName returns the current POD's name from env var POD_NAME,
+// falling back to the hostname.
+func findMyPodName() (string, error) {
+	if name := os.Getenv("POD_NAME"); name != "" {
+		return name, nil
+	}
+	return os.Hostname()
+}
+
+// findMyPod retrieves the current POD object from kubernetes API.
+func findMyPod(ctx context.Context, options Options) (*corev1.Pod, error) {
+	name, errName := findMyPodName()
+	if errName != nil {
+		return nil, errName
+	}
+	namespace, errNs := findMyNamespace(options)
+	if errNs != nil {
+		return nil, errNs
+	}
+	pod, err := options.Client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get my pod: namespace=%s name=%s: %w",
+			namespace, name, err)
+	}
+	return pod, nil
+}
+
+// selectorFromPod builds a label selector matching the values of
+// the given label keys in the POD's labels.
+func selectorFromPod(pod *corev1.Pod, keys []string) (string, error) {
+	set := labels.Set{}
+	for _, k := range keys {
+		v, found := pod.Labels[k]
+		if !found {
+			return "", fmt.Errorf("%w: pod %s/%s: missing label '%s'",
+				ErrInvalidLabelSelector, pod.Namespace, pod.Name, k)
+		}
+		set[k] = v
+	}
+	return labels.SelectorFromSet(set).String(), nil
+}
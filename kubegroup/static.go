@@ -0,0 +1,173 @@
+package kubegroup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// StaticDiscoverer reports a fixed list of peers.
+// It is useful for running outside kubernetes.
+type StaticDiscoverer struct {
+	peers []PeerCandidate
+}
+
+// NewStaticDiscoverer creates a StaticDiscoverer for the given peer
+// addresses. Each address is "host", "host:port" or a URL like
+// "http://host:port". If port is missing, Options.GroupCachePort is used.
+func NewStaticDiscoverer(addresses ...string) (*StaticDiscoverer, error) {
+	peers, err := parsePeerAddresses(addresses)
+	if err != nil {
+		return nil, err
+	}
+	return &StaticDiscoverer{peers: peers}, nil
+}
+
+// Run implements Discoverer.
+func (d *StaticDiscoverer) Run(ctx context.Context, onUpdate func(peers []PeerCandidate)) error {
+	onUpdate(slices.Clone(d.peers))
+	<-ctx.Done()
+	return nil
+}
+
+// FileDiscovererOptions specifies options for NewFileDiscoverer.
+type FileDiscovererOptions struct {
+	// Path is required. It is the file holding peer addresses.
+	// Files ending in .json hold a JSON list of addresses.
+	// Files ending in .yaml or .yml hold a YAML list of addresses.
+	// Other files hold one address per line; blank lines and lines
+	// starting with # are ignored.
+	// Addresses follow the format accepted by NewStaticDiscoverer.
+	Path string
+
+	// PollInterval is the interval for checking the file for changes.
+	// Default is 5 seconds.
+	PollInterval time.Duration
+}
+
+// FileDiscoverer reports peers read from a file, reloading the file
+// when it changes.
+type FileDiscoverer struct {
+	options FileDiscovererOptions
+}
+
+// NewFileDiscoverer creates a FileDiscoverer.
+func NewFileDiscoverer(options FileDiscovererOptions) *FileDiscoverer {
+	if options.PollInterval == 0 {
+		options.PollInterval = 5 * time.Second
+	}
+	return &FileDiscoverer{options: options}
+}
+
+// Run implements Discoverer. Run returns an error if the file cannot
+// be loaded.
+func (d *FileDiscoverer) Run(ctx context.Context, onUpdate func(peers []PeerCandidate)) error {
+	ticker := time.NewTicker(d.options.PollInterval)
+	defer ticker.Stop()
+
+	var last []byte
+	var loaded bool
+
+	for {
+		data, errRead := os.ReadFile(d.options.Path)
+		if errRead != nil {
+			return errRead
+		}
+
+		if !loaded || !bytes.Equal(data, last) {
+			peers, err := d.parse(data)
+			if err != nil {
+				return err
+			}
+			onUpdate(peers)
+			last = data
+			loaded = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *FileDiscoverer) parse(data []byte) ([]PeerCandidate, error) {
+	var addresses []string
+
+	switch strings.ToLower(filepath.Ext(d.options.Path)) {
+	case ".json":
+		if err := json.Unmarshal(data, &addresses); err != nil {
+			return nil, fmt.Errorf("file discoverer: %s: %w", d.options.Path, err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &addresses); err != nil {
+			return nil, fmt.Errorf("file discoverer: %s: %w", d.options.Path, err)
+		}
+	default:
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			addresses = append(addresses, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("file discoverer: %s: %w", d.options.Path, err)
+		}
+	}
+
+	peers, err := parsePeerAddresses(addresses)
+	if err != nil {
+		return nil, fmt.Errorf("file discoverer: %s: %w", d.options.Path, err)
+	}
+
+	return peers, nil
+}
+
+// parsePeerAddresses parses "host", "host:port" or "scheme://host:port".
+func parsePeerAddresses(addresses []string) ([]PeerCandidate, error) {
+	peers := make([]PeerCandidate, 0, len(addresses))
+
+	for _, addr := range addresses {
+		hostPort := addr
+		if strings.Contains(addr, "://") {
+			u, err := url.Parse(addr)
+			if err != nil {
+				return nil, fmt.Errorf("bad peer address '%s': %w", addr, err)
+			}
+			hostPort = u.Host
+		}
+
+		host, port := hostPort, ""
+		if h, p, err := net.SplitHostPort(hostPort); err == nil {
+			host, port = h, ":"+p
+		} else if inner, found := strings.CutPrefix(hostPort, "["); found {
+			host = strings.TrimSuffix(inner, "]") // IPv6 without port
+		}
+		if host == "" {
+			return nil, fmt.Errorf("bad peer address '%s': missing host", addr)
+		}
+
+		peers = append(peers, PeerCandidate{
+			Name:  addr,
+			IP:    host,
+			Port:  port,
+			Ready: true,
+		})
+	}
+
+	return peers, nil
+}
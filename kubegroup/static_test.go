@@ -0,0 +1,108 @@
+package kubegroup
+
+import "testing"
+
+func TestParsePeerAddresses(t *testing.T) {
+	table := []struct {
+		addr string
+		host string
+		port string
+	}{
+		{"10.0.0.1", "10.0.0.1", ""},
+		{"10.0.0.1:5000", "10.0.0.1", ":5000"},
+		{"cache1", "cache1", ""},
+		{"cache1:5000", "cache1", ":5000"},
+		{"[fd00::1]", "fd00::1", ""},
+		{"[fd00::1]:5000", "fd00::1", ":5000"},
+		{"http://cache1:5000", "cache1", ":5000"},
+		{"http://[fd00::2]", "fd00::2", ""},
+		{"https://[fd00::2]:5000/_groupcache/", "fd00::2", ":5000"},
+	}
+
+	for _, data := range table {
+		peers, err := parsePeerAddresses([]string{data.addr})
+		if err != nil {
+			t.Errorf("%s: %v", data.addr, err)
+			continue
+		}
+		p := peers[0]
+		if p.IP != data.host || p.Port != data.port || !p.Ready {
+			t.Errorf("%s: expected host=%s port=%s, got %+v",
+				data.addr, data.host, data.port, p)
+		}
+	}
+
+	for _, bad := range []string{":5000", "http://:5000", "http://%zz"} {
+		if _, err := parsePeerAddresses([]string{bad}); err == nil {
+			t.Errorf("%s: expected error", bad)
+		}
+	}
+}
+
+func TestStaticPeerAddressIPv6(t *testing.T) {
+	g := newTestGroup(t, Options{Pool: &fakePool{}, GroupCachePort: ":5000", Scheme: "http"})
+
+	peers, err := parsePeerAddresses([]string{"[fd00::1]", "http://[fd00::2]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, want := range []string{"http://[fd00::1]:5000", "http://[fd00::2]:5000"} {
+		info, _ := g.peerInfo(peers[i], g.getStatus().LastUpdate)
+		if info.Address != want {
+			t.Errorf("expected %s, got %s", want, info.Address)
+		}
+	}
+}
+
+func TestStaticPeerIsSelf(t *testing.T) {
+	g := newTestGroup(t, Options{Peers: &fakePeerSet{}, GroupCachePort: ":5000"})
+	g.myPodName = "cache1"
+	g.myAddr = "127.0.0.1"
+
+	peers, err := parsePeerAddresses([]string{
+		"cache1:5000",    // hostname
+		"localhost:5000", // resolves to own address
+		"cache1:5001",    // another instance on the same host
+		"cache2:5000",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, want := range []bool{true, true, false, false} {
+		if got := g.isSelf(peers[i], []string{peers[i].IP}); got != want {
+			t.Errorf("%s: expected self=%t, got %t", peers[i].Name, want, got)
+		}
+	}
+}
+
+func TestFileDiscovererParse(t *testing.T) {
+	table := []struct {
+		file    string
+		content string
+	}{
+		{"peers.json", `["10.0.0.1:5000", "cache2"]`},
+		{"peers.yaml", "- 10.0.0.1:5000\n- cache2\n"},
+		{"peers.yml", "- 10.0.0.1:5000\n- cache2\n"},
+		{"peers.txt", "# peers\n10.0.0.1:5000\n\n  cache2  \n"},
+	}
+
+	for _, data := range table {
+		d := NewFileDiscoverer(FileDiscovererOptions{Path: data.file})
+		peers, err := d.parse([]byte(data.content))
+		if err != nil {
+			t.Errorf("%s: %v", data.file, err)
+			continue
+		}
+		if len(peers) != 2 || peers[0].IP != "10.0.0.1" || peers[0].Port != ":5000" ||
+			peers[1].IP != "cache2" || peers[1].Port != "" {
+			t.Errorf("%s: unexpected peers: %+v", data.file, peers)
+		}
+	}
+
+	d := NewFileDiscoverer(FileDiscovererOptions{Path: "peers.json"})
+	if _, err := d.parse([]byte("not json")); err == nil {
+		t.Errorf("expected error for invalid json")
+	}
+}
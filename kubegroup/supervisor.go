@@ -0,0 +1,95 @@
+package kubegroup
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// supervise runs the informer, restarting it with exponential backoff
+// whenever it exits, until the group context is canceled or
+// InformerMaxRestarts is exceeded.
+func (g *Group) supervise() {
+	defer close(g.done)
+
+	var restarts int
+
+	for {
+		begin := time.Now()
+
+		stopped, errInformer := g.runInformer()
+		if stopped {
+			g.setErr(context.Cause(g.ctx))
+			return
+		}
+
+		g.errorf("informer exited, error: %v", errInformer)
+		if errInformer == nil {
+			errInformer = ErrInformerExited
+		}
+
+		if time.Since(begin) > g.options.InformerRestartMaxDelay {
+			restarts = 0 // informer was healthy for a while
+		}
+
+		maxRestarts := g.options.InformerMaxRestarts
+		if maxRestarts > 0 && restarts >= maxRestarts {
+			err := fmt.Errorf("%w: %d: %w", ErrInformerMaxRestarts,
+				maxRestarts, errInformer)
+			g.errorf("giving up: %v", err)
+			g.setErr(err)
+			g.cancel(err)
+			if g.options.OnInformerFailure != nil {
+				g.options.OnInformerFailure(err)
+			}
+			return
+		}
+
+		delay := backoff(restarts, g.options.InformerRestartMinDelay,
+			g.options.InformerRestartMaxDelay)
+
+		g.debugf("restarting informer in %v (restart %d)", delay, restarts+1)
+
+		select {
+		case <-g.ctx.Done():
+			g.setErr(context.Cause(g.ctx))
+			return
+		case <-time.After(delay):
+		}
+
+		restarts++
+		g.m.restart()
+	}
+}
+
+// runInformer runs the discoverer until it exits or the group context
+// is canceled. stopped is true when the group context was canceled.
+func (g *Group) runInformer() (stopped bool, err error) {
+	ctx, cancel := context.WithCancel(g.ctx)
+	defer cancel()
+
+	errInformer := g.discoverer.Run(ctx, g.onUpdate)
+
+	if g.ctx.Err() != nil {
+		g.debugf("informer stopped, error: %v", errInformer)
+		return true, errInformer
+	}
+
+	return false, errInformer
+}
+
+// backoff returns exponential delay for the given attempt,
+// capped at maxDelay, with jitter in the range [delay/2, delay].
+func backoff(attempt int, minDelay, maxDelay time.Duration) time.Duration {
+	delay := min(minDelay, maxDelay)
+	for range attempt {
+		delay *= 2
+		if delay >= maxDelay {
+			delay = maxDelay
+			break
+		}
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
@@ -0,0 +1,134 @@
+package kubegroup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	minDelay, maxDelay := 10*time.Millisecond, time.Second
+
+	for attempt := range 12 {
+		delay := min(minDelay<<attempt, maxDelay)
+		for range 100 {
+			got := backoff(attempt, minDelay, maxDelay)
+			if got < delay/2 || got > delay {
+				t.Fatalf("attempt=%d: expected delay in [%v, %v], got %v",
+					attempt, delay/2, delay, got)
+			}
+		}
+	}
+}
+
+var errDiscoverer = errors.New("discoverer failure")
+
+// failingDiscoverer fails every run after sleeping for duration.
+// Once runs reach block, it runs until ctx is done.
+type failingDiscoverer struct {
+	duration time.Duration
+	block    int32
+	runs     atomic.Int32
+}
+
+func (d *failingDiscoverer) Run(ctx context.Context, _ func(peers []PeerCandidate)) error {
+	if d.runs.Add(1) >= d.block && d.block > 0 {
+		<-ctx.Done()
+		return nil
+	}
+	time.Sleep(d.duration)
+	return errDiscoverer
+}
+
+func waitDone(t *testing.T, g *Group) {
+	t.Helper()
+	select {
+	case <-g.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("group not done")
+	}
+}
+
+func TestSuperviseMaxRestarts(t *testing.T) {
+	var failure error
+	var calls int
+
+	g := newTestGroup(t, Options{
+		InformerRestartMinDelay: time.Millisecond,
+		InformerRestartMaxDelay: time.Second,
+		InformerMaxRestarts:     2,
+		OnInformerFailure: func(err error) {
+			calls++
+			failure = err
+		},
+	})
+	d := &failingDiscoverer{}
+	g.discoverer = d
+
+	go g.supervise()
+	waitDone(t, g)
+
+	err := g.Err()
+	if !errors.Is(err, ErrInformerMaxRestarts) || !errors.Is(err, errDiscoverer) {
+		t.Errorf("expected max restarts error wrapping discoverer failure, got %v", err)
+	}
+	if calls != 1 || failure != err {
+		t.Errorf("expected one OnInformerFailure call with %v, got %d calls with %v",
+			err, calls, failure)
+	}
+	if runs := d.runs.Load(); runs != 3 {
+		t.Errorf("expected initial run plus 2 restarts, got %d runs", runs)
+	}
+	if !errors.Is(context.Cause(g.ctx), ErrInformerMaxRestarts) {
+		t.Errorf("group context must be canceled, cause: %v", context.Cause(g.ctx))
+	}
+}
+
+func TestSuperviseResetsRestarts(t *testing.T) {
+	var calls atomic.Int32
+
+	// each run outlives InformerRestartMaxDelay, so restarts never add up
+	g := newTestGroup(t, Options{
+		InformerRestartMinDelay: time.Millisecond,
+		InformerRestartMaxDelay: time.Millisecond,
+		InformerMaxRestarts:     1,
+		OnInformerFailure:       func(error) { calls.Add(1) },
+	})
+	d := &failingDiscoverer{duration: 5 * time.Millisecond, block: 5}
+	g.discoverer = d
+
+	go g.supervise()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for d.runs.Load() < d.block {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d runs, got %d", d.block, d.runs.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	g.cancel(ErrClosed)
+	waitDone(t, g)
+
+	if err := g.Err(); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected %v, got %v", ErrClosed, err)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("unexpected OnInformerFailure calls: %d", n)
+	}
+}
+
+func TestSuperviseStopped(t *testing.T) {
+	g := newTestGroup(t, Options{})
+	g.discoverer = blockingDiscoverer{}
+
+	go g.supervise()
+	g.cancel(ErrClosed)
+	waitDone(t, g)
+
+	if err := g.Err(); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected %v, got %v", ErrClosed, err)
+	}
+}
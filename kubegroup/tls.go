@@ -0,0 +1,284 @@
+package kubegroup
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSOptions specifies options for NewTLSTransport and NewTLSServerConfig.
+// Certificate files are reloaded when they change, so rotated
+// certificates are picked up without restart.
+type TLSOptions struct {
+	// CertFile and KeyFile hold the PEM certificate and key presented
+	// to the other side. They are required for NewTLSServerConfig, and
+	// enable mutual TLS (client certificate) for NewTLSTransport.
+	CertFile string
+	KeyFile  string
+
+	// CAFile optionally holds PEM CA certificates for verifying the other
+	// side. For NewTLSTransport, if CAFile is undefined, system roots are
+	// used. For NewTLSServerConfig, CAFile enables mutual TLS by requiring
+	// client certificates signed by these CAs.
+	CAFile string
+
+	// ServerName optionally overrides the name verified against server
+	// certificates. Peers are addressed by IP, so either certificates
+	// carry IP SANs or ServerName is set to a name present in them.
+	ServerName string
+
+	// ReloadInterval is the minimum interval between checks for changed
+	// certificate files. Default is 1 minute.
+	ReloadInterval time.Duration
+
+	// Logf reports certificate reload failures. Default is log.Printf.
+	Logf func(format string, v ...any)
+}
+
+// NewTLSTransport creates an http.Transport for reaching peers over
+// HTTPS, for instance for groupcache.HTTPPoolOptions.Transport.
+// Use it with Options.Scheme set to "https".
+// Server certificates are verified against the dialed peer address,
+// which is usually the POD IP, unless ServerName is defined.
+func NewTLSTransport(options TLSOptions) (*http.Transport, error) {
+	r, err := newCertReloader(options)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: options.ServerName,
+	}
+
+	if options.CertFile != "" {
+		config.GetClientCertificate = func(_ *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.certificate()
+		}
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+
+	// The dialed host is known only per connection, so each connection
+	// gets its own config verifying the peer against that host.
+	transport.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, errSplit := net.SplitHostPort(addr)
+		if errSplit != nil {
+			return nil, errSplit
+		}
+
+		name := options.ServerName
+		if name == "" {
+			name = host
+		}
+
+		c := config.Clone()
+		c.ServerName = name
+
+		if options.CAFile != "" {
+			// Verification is performed by VerifyConnection against the
+			// reloadable CA pool, since RootCAs cannot be swapped.
+			c.InsecureSkipVerify = true
+			c.VerifyConnection = func(cs tls.ConnectionState) error {
+				if name == "" {
+					return errors.New("tls: empty server name, refusing to skip verification")
+				}
+				return r.verify(cs, name, x509.ExtKeyUsageServerAuth)
+			}
+		}
+
+		conn, errDial := dialer.DialContext(ctx, network, addr)
+		if errDial != nil {
+			return nil, errDial
+		}
+
+		tlsConn := tls.Client(conn, c)
+		if errHandshake := tlsConn.HandshakeContext(ctx); errHandshake != nil {
+			conn.Close()
+			return nil, errHandshake
+		}
+
+		return tlsConn, nil
+	}
+
+	return transport, nil
+}
+
+// NewTLSServerConfig creates a tls.Config for the groupcache peering
+// server, matching NewTLSTransport. If CAFile is defined, clients must
+// present certificates signed by those CAs (mutual TLS).
+func NewTLSServerConfig(options TLSOptions) (*tls.Config, error) {
+	if options.CertFile == "" || options.KeyFile == "" {
+		return nil, errors.New("tls server config: CertFile and KeyFile are required")
+	}
+
+	r, err := newCertReloader(options)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return r.certificate()
+		},
+	}
+
+	if options.CAFile != "" {
+		config.ClientAuth = tls.RequireAnyClientCert
+		config.VerifyConnection = func(cs tls.ConnectionState) error {
+			return r.verify(cs, "", x509.ExtKeyUsageClientAuth)
+		}
+	}
+
+	return config, nil
+}
+
+// certReloader holds certificate and CA pool loaded from files,
+// reloading them when file modification times change.
+type certReloader struct {
+	options TLSOptions
+
+	mutex     sync.Mutex
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+	modTimes  map[string]time.Time
+	lastCheck time.Time
+}
+
+func newCertReloader(options TLSOptions) (*certReloader, error) {
+	if options.ReloadInterval == 0 {
+		options.ReloadInterval = time.Minute
+	}
+	if options.Logf == nil {
+		options.Logf = log.Printf
+	}
+	r := &certReloader{options: options, lastCheck: time.Now()}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) files() []string {
+	var files []string
+	for _, f := range []string{r.options.CertFile, r.options.KeyFile, r.options.CAFile} {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// load reads all files and replaces certificate and CA pool at once,
+// so a failed load keeps the previous state intact. Caller must hold
+// mutex, or own r.
+func (r *certReloader) load() error {
+	modTimes := map[string]time.Time{}
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			return err
+		}
+		modTimes[f] = info.ModTime()
+	}
+
+	var cert *tls.Certificate
+	if r.options.CertFile != "" {
+		c, err := tls.LoadX509KeyPair(r.options.CertFile, r.options.KeyFile)
+		if err != nil {
+			return err
+		}
+		cert = &c
+	}
+
+	var pool *x509.CertPool
+	if r.options.CAFile != "" {
+		data, err := os.ReadFile(r.options.CAFile)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("tls: %s: no CA certificate found", r.options.CAFile)
+		}
+	}
+
+	r.cert = cert
+	r.pool = pool
+	r.modTimes = modTimes
+
+	return nil
+}
+
+// refresh reloads files if they changed. A failed reload is logged and
+// keeps the previous certificates, so a partially written rotation
+// does not break peering; it is retried after ReloadInterval.
+func (r *certReloader) refresh() {
+	if time.Since(r.lastCheck) < r.options.ReloadInterval {
+		return
+	}
+	r.lastCheck = time.Now()
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			r.options.Logf("WARN kubegroup: tls reload: %v", err)
+			return
+		}
+		if !info.ModTime().Equal(r.modTimes[f]) {
+			if err := r.load(); err != nil {
+				r.options.Logf("WARN kubegroup: tls reload: keeping previous certificates: %v", err)
+			}
+			return
+		}
+	}
+}
+
+func (r *certReloader) certificate() (*tls.Certificate, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.refresh()
+	return r.cert, nil
+}
+
+// verify checks the peer certificate chain against the CA pool.
+// Empty name skips host name verification.
+func (r *certReloader) verify(cs tls.ConnectionState, name string,
+	usage x509.ExtKeyUsage) error {
+
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("tls: no peer certificate")
+	}
+
+	r.mutex.Lock()
+	r.refresh()
+	pool := r.pool
+	r.mutex.Unlock()
+
+	intermediates := x509.NewCertPool()
+	for _, c := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(c)
+	}
+
+	_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: intermediates,
+		DNSName:       name,
+		KeyUsages:     []x509.ExtKeyUsage{usage},
+	})
+	return err
+}
@@ -0,0 +1,270 @@
+package kubegroup
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA is a certificate authority for tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+var testSerial int64
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSerial++
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(testSerial),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue returns PEM certificate and key signed by ca.
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage,
+	ips ...string) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSerial++
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(testSerial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	for _, ip := range ips {
+		tmpl.IPAddresses = append(tmpl.IPAddresses, net.ParseIP(ip))
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeFiles writes TLS files into dir and returns their options.
+// Modification times are moved forward to make rotations visible.
+func writeFiles(t *testing.T, dir string, cert, key, ca []byte, modTime time.Time) TLSOptions {
+	t.Helper()
+	options := TLSOptions{
+		CertFile:       filepath.Join(dir, "tls.crt"),
+		KeyFile:        filepath.Join(dir, "tls.key"),
+		CAFile:         filepath.Join(dir, "ca.crt"),
+		ReloadInterval: time.Nanosecond,
+		Logf:           t.Logf,
+	}
+	for f, data := range map[string][]byte{
+		options.CertFile: cert,
+		options.KeyFile:  key,
+		options.CAFile:   ca,
+	} {
+		if err := os.WriteFile(f, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(f, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return options
+}
+
+// startTLSServer serves on 127.0.0.1 and returns the server URL.
+func startTLSServer(t *testing.T, options TLSOptions) string {
+	t.Helper()
+	config, err := NewTLSServerConfig(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}),
+	}
+	go server.Serve(tls.NewListener(listener, config))
+	t.Cleanup(func() { server.Close() })
+	return "https://" + listener.Addr().String()
+}
+
+// get sends a request and returns the server certificate common name.
+func get(t *testing.T, options TLSOptions, url string) (string, error) {
+	t.Helper()
+	transport, err := NewTLSTransport(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport.DisableKeepAlives = true
+	resp, err := (&http.Client{Transport: transport}).Get(url)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return resp.TLS.PeerCertificates[0].Subject.CommonName, nil
+}
+
+func TestTLSMutual(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	now := time.Now()
+
+	serverCert, serverKey := ca.issue(t, "server", x509.ExtKeyUsageServerAuth, "127.0.0.1")
+	url := startTLSServer(t, writeFiles(t, t.TempDir(), serverCert, serverKey, ca.pem, now))
+
+	clientCert, clientKey := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+	if _, err := get(t, writeFiles(t, t.TempDir(), clientCert, clientKey, ca.pem, now), url); err != nil {
+		t.Errorf("mutual tls: %v", err)
+	}
+}
+
+func TestTLSClientWithoutCertificate(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	now := time.Now()
+
+	serverCert, serverKey := ca.issue(t, "server", x509.ExtKeyUsageServerAuth, "127.0.0.1")
+	url := startTLSServer(t, writeFiles(t, t.TempDir(), serverCert, serverKey, ca.pem, now))
+
+	options := writeFiles(t, t.TempDir(), serverCert, serverKey, ca.pem, now)
+	options.CertFile = ""
+	options.KeyFile = ""
+	if _, err := get(t, options, url); err == nil {
+		t.Errorf("expected rejection of client without certificate")
+	}
+}
+
+func TestTLSWrongCA(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	otherCA := newTestCA(t, "other-ca")
+	now := time.Now()
+
+	serverCert, serverKey := ca.issue(t, "server", x509.ExtKeyUsageServerAuth, "127.0.0.1")
+	url := startTLSServer(t, writeFiles(t, t.TempDir(), serverCert, serverKey, ca.pem, now))
+
+	// client trusts another CA
+	clientCert, clientKey := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+	if _, err := get(t, writeFiles(t, t.TempDir(), clientCert, clientKey, otherCA.pem, now), url); err == nil {
+		t.Errorf("expected rejection of server certificate from unknown CA")
+	}
+
+	// client certificate from another CA
+	otherCert, otherKey := otherCA.issue(t, "client", x509.ExtKeyUsageClientAuth)
+	if _, err := get(t, writeFiles(t, t.TempDir(), otherCert, otherKey, ca.pem, now), url); err == nil {
+		t.Errorf("expected rejection of client certificate from unknown CA")
+	}
+}
+
+func TestTLSWrongIP(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	now := time.Now()
+
+	// server certificate for another peer
+	serverCert, serverKey := ca.issue(t, "server", x509.ExtKeyUsageServerAuth, "127.0.0.2")
+	url := startTLSServer(t, writeFiles(t, t.TempDir(), serverCert, serverKey, ca.pem, now))
+
+	clientCert, clientKey := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+	if _, err := get(t, writeFiles(t, t.TempDir(), clientCert, clientKey, ca.pem, now), url); err == nil {
+		t.Errorf("expected rejection of server certificate for wrong IP")
+	}
+}
+
+func TestTLSReload(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	now := time.Now()
+
+	serverDir := t.TempDir()
+	serverCert, serverKey := ca.issue(t, "server-1", x509.ExtKeyUsageServerAuth, "127.0.0.1")
+	url := startTLSServer(t, writeFiles(t, serverDir, serverCert, serverKey, ca.pem, now))
+
+	clientCert, clientKey := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+	clientOptions := writeFiles(t, t.TempDir(), clientCert, clientKey, ca.pem, now)
+
+	name, err := get(t, clientOptions, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "server-1" {
+		t.Errorf("expected server-1, got %s", name)
+	}
+
+	// rotate server certificate
+	serverCert, serverKey = ca.issue(t, "server-2", x509.ExtKeyUsageServerAuth, "127.0.0.1")
+	writeFiles(t, serverDir, serverCert, serverKey, ca.pem, now.Add(time.Minute))
+
+	name, err = get(t, clientOptions, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "server-2" {
+		t.Errorf("expected rotated server-2, got %s", name)
+	}
+}
+
+func TestTLSReloadFailureKeepsPrevious(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	now := time.Now()
+
+	dir := t.TempDir()
+	cert, key := ca.issue(t, "server-1", x509.ExtKeyUsageServerAuth, "127.0.0.1")
+	options := writeFiles(t, dir, cert, key, ca.pem, now)
+
+	r, err := newCertReloader(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevCert, prevPool := r.cert, r.pool
+
+	// rotation with a broken CA file
+	cert, key = ca.issue(t, "server-2", x509.ExtKeyUsageServerAuth, "127.0.0.1")
+	writeFiles(t, dir, cert, key, []byte("broken"), now.Add(time.Minute))
+
+	got, _ := r.certificate()
+	if got != prevCert || r.pool != prevPool {
+		t.Errorf("failed reload must keep previous certificate and CA pool")
+	}
+}
@@ -0,0 +1,83 @@
+package kubegroup
+
+import "fmt"
+
+// Topology selects how peers are restricted by zone.
+// A peer's zone is taken from label topology.kubernetes.io/zone of its
+// node, or from the EndpointSlice endpoint zone.
+type Topology string
+
+const (
+	// TopologyNone peers with all zones.
+	TopologyNone Topology = ""
+
+	// TopologySameZone peers only with peers in the current POD's zone.
+	TopologySameZone Topology = "SameZone"
+
+	// TopologyPreferSameZone peers with peers in the current POD's zone,
+	// falling back to all zones when the zone has fewer than
+	// Options.TopologyMinPeers ready peers.
+	TopologyPreferSameZone Topology = "PreferSameZone"
+)
+
+func (t Topology) validate() error {
+	switch t {
+	case TopologyNone, TopologySameZone, TopologyPreferSameZone:
+		return nil
+	}
+	return fmt.Errorf("%w: '%s'", ErrInvalidTopology, t)
+}
+
+// selfZone returns the current POD's zone: Options.Zone, if defined,
+// otherwise the zone of the peer identified as self.
+func (g *Group) selfZone(infos []PeerInfo) string {
+	if g.options.Zone != "" {
+		return g.options.Zone
+	}
+	for _, p := range infos {
+		if p.IsSelf {
+			return p.Zone
+		}
+	}
+	return ""
+}
+
+// filterTopology restricts ready peers to zone according to
+// Options.Topology. If zone is unknown, all peers are kept.
+func (g *Group) filterTopology(ready []PeerInfo, zone string) []PeerInfo {
+	if g.options.Topology == TopologyNone {
+		return ready
+	}
+
+	if zone == "" {
+		g.debugf("topology: own zone unknown, peering with all zones")
+		return ready
+	}
+
+	local := make([]PeerInfo, 0, len(ready))
+	for _, p := range ready {
+		if p.Zone == zone {
+			local = append(local, p)
+		}
+	}
+
+	if g.options.Topology == TopologyPreferSameZone &&
+		len(local) < g.options.TopologyMinPeers {
+		g.debugf("topology: zone=%s: %d ready peers, fewer than %d, peering with all zones",
+			zone, len(local), g.options.TopologyMinPeers)
+		return ready
+	}
+
+	return local
+}
+
+// zonePeers counts peers per zone.
+func zonePeers(infos []PeerInfo) map[string]int {
+	zones := map[string]int{}
+	for _, p := range infos {
+		if p.Zone != "" {
+			zones[p.Zone]++
+		}
+	}
+	return zones
+}
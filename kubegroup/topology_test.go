@@ -0,0 +1,110 @@
+package kubegroup
+
+import (
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	listerscorev1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+var topologyPeers = []PeerInfo{
+	{Name: "self", Zone: "zone-a", IsSelf: true},
+	{Name: "a1", Zone: "zone-a"},
+	{Name: "b1", Zone: "zone-b"},
+	{Name: "b2", Zone: "zone-b"},
+	{Name: "unknown"},
+}
+
+func peerNames(peers []PeerInfo) []string {
+	var list []string
+	for _, p := range peers {
+		list = append(list, p.Name)
+	}
+	return list
+}
+
+func TestSelfZone(t *testing.T) {
+	g := newTestGroup(t, Options{})
+	if got := g.selfZone(topologyPeers); got != "zone-a" {
+		t.Errorf("expected zone-a from self peer, got %s", got)
+	}
+	if got := g.selfZone(topologyPeers[1:]); got != "" {
+		t.Errorf("expected unknown zone without self, got %s", got)
+	}
+
+	g.options.Zone = "zone-b"
+	if got := g.selfZone(topologyPeers); got != "zone-b" {
+		t.Errorf("expected forced zone-b, got %s", got)
+	}
+}
+
+func TestFilterTopology(t *testing.T) {
+	all := peerNames(topologyPeers)
+
+	table := []struct {
+		name     string
+		topology Topology
+		minPeers int
+		zone     string
+		want     []string
+	}{
+		{"none", TopologyNone, 2, "zone-a", all},
+		{"same zone", TopologySameZone, 2, "zone-a", []string{"self", "a1"}},
+		{"same zone ignores min peers", TopologySameZone, 5, "zone-a", []string{"self", "a1"}},
+		{"prefer with enough peers", TopologyPreferSameZone, 2, "zone-a", []string{"self", "a1"}},
+		{"prefer falls back", TopologyPreferSameZone, 3, "zone-a", all},
+		{"unknown zone", TopologySameZone, 2, "", all},
+	}
+
+	for _, data := range table {
+		g := newTestGroup(t, Options{Topology: data.topology, TopologyMinPeers: data.minPeers})
+		got := peerNames(g.filterTopology(topologyPeers, data.zone))
+		if !slices.Equal(got, data.want) {
+			t.Errorf("%s: expected %v, got %v", data.name, data.want, got)
+		}
+	}
+}
+
+func TestZonePeers(t *testing.T) {
+	got := zonePeers(topologyPeers)
+	if len(got) != 2 || got["zone-a"] != 2 || got["zone-b"] != 2 {
+		t.Errorf("unexpected zone distribution: %v", got)
+	}
+}
+
+func TestNodeZone(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   "node-1",
+		Labels: map[string]string{corev1.LabelTopologyZone: "zone-a"},
+	}})
+	nodes := listerscorev1.NewNodeLister(indexer)
+
+	if got := nodeZone(nodes, "node-1"); got != "zone-a" {
+		t.Errorf("expected zone-a, got %s", got)
+	}
+	if got := nodeZone(nodes, "node-2"); got != "" {
+		t.Errorf("expected unknown zone for missing node, got %s", got)
+	}
+	if got := nodeZone(nil, "node-1"); got != "" {
+		t.Errorf("expected unknown zone without lister, got %s", got)
+	}
+}
+
+func TestLabelsChanged(t *testing.T) {
+	node := func(zone string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-1",
+			Labels: map[string]string{corev1.LabelTopologyZone: zone},
+		}}
+	}
+	if labelsChanged(node("zone-a"), node("zone-a")) {
+		t.Errorf("status-only node update must not trigger a list")
+	}
+	if !labelsChanged(node("zone-a"), node("zone-b")) {
+		t.Errorf("zone change must trigger a list")
+	}
+}
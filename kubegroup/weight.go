@@ -0,0 +1,113 @@
+package kubegroup
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// WeightedPeer is a peer delivered with its weight.
+// See WeightedPeerGroup and WeightedPeerSet.
+type WeightedPeer struct {
+	// Address is the peer address: URL for groupcache2 (Pool),
+	// host:port for groupcache3 (Peers).
+	Address string
+
+	// IsSelf reports whether the peer is the current POD.
+	IsSelf bool
+
+	// Weight is the peer weight, at least 1.
+	Weight int
+}
+
+// WeightedPeerGroup is an optional interface for Options.Pool.
+// If Pool implements WeightedPeerGroup, peers are delivered with
+// SetWeighted instead of Set.
+type WeightedPeerGroup interface {
+	SetWeighted(peers []WeightedPeer)
+}
+
+// WeightedPeerSet is an optional interface for Options.Peers.
+// If Peers implements WeightedPeerSet, peers are delivered with
+// SetWeightedPeers instead of SetPeers.
+type WeightedPeerSet interface {
+	SetWeightedPeers(ctx context.Context, peers []WeightedPeer) error
+}
+
+func weightedPeers(ready []PeerInfo) []WeightedPeer {
+	peers := make([]WeightedPeer, 0, len(ready))
+	for _, p := range ready {
+		peers = append(peers, WeightedPeer{
+			Address: p.Address,
+			IsSelf:  p.IsSelf,
+			Weight:  p.Weight,
+		})
+	}
+	return peers
+}
+
+// virtualPeerPath prefixes the URL path of virtual peer entries.
+const virtualPeerPath = "/_kubegroup/v"
+
+// virtualPeers returns weight entries for a groupcache2 peer URL: the
+// URL itself plus weight-1 virtual entries reaching the same peer
+// under a distinct path, like "http://10.0.0.1:5000/_kubegroup/v1".
+// See VirtualPeerHandler.
+func virtualPeers(addr string, weight int) []string {
+	peers := []string{addr}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return peers
+	}
+	for i := 1; i < weight; i++ {
+		v := *u
+		v.Path = virtualPeerPath + strconv.Itoa(i) + u.Path
+		peers = append(peers, v.String())
+	}
+	return peers
+}
+
+// VirtualPeerHandler wraps the groupcache2 pool handler to serve
+// requests addressed to virtual peer entries, by stripping the virtual
+// entry prefix from the request path. It is required on every peer when
+// Options.VirtualPeers is enabled, and must be rolled out before
+// enabling it. Requests without the virtual entry prefix are passed
+// unchanged, so it is safe to deploy while VirtualPeers is disabled.
+func VirtualPeerHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rest, found := strings.CutPrefix(r.URL.Path, virtualPeerPath); found {
+			if replica, path, ok := strings.Cut(rest, "/"); ok {
+				prefix := virtualPeerPath + replica
+				r2 := new(http.Request)
+				*r2 = *r
+				r2.URL = new(url.URL)
+				*r2.URL = *r.URL
+				r2.URL.Path = "/" + path
+				r2.URL.RawPath = strings.TrimPrefix(r.URL.RawPath, prefix)
+				r = r2
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// peerWeight resolves candidate weight, trying in order: annotation
+// WeightAnnotation, candidate Weight. Default is 1. Weight is capped
+// at MaxWeight.
+func (g *Group) peerWeight(p PeerCandidate) int {
+	weight := p.Weight
+
+	if value, found := p.Annotations[g.options.WeightAnnotation]; found {
+		if w, err := strconv.Atoi(value); err == nil && w > 0 {
+			weight = w
+		} else {
+			g.warnOnce("weight/"+p.Namespace+"/"+p.Name+"="+value,
+				"pod %s/%s: annotation %s=%s: invalid weight, ignoring",
+				p.Namespace, p.Name, g.options.WeightAnnotation, value)
+		}
+	}
+
+	return min(max(weight, 1), g.options.MaxWeight)
+}
@@ -0,0 +1,89 @@
+package kubegroup
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestVirtualPeers(t *testing.T) {
+	got := virtualPeers("http://10.0.0.1:5000", 3)
+	want := []string{
+		"http://10.0.0.1:5000",
+		"http://10.0.0.1:5000/_kubegroup/v1",
+		"http://10.0.0.1:5000/_kubegroup/v2",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if got := virtualPeers("http://10.0.0.1:5000", 1); len(got) != 1 {
+		t.Errorf("weight 1 must not add virtual entries: %v", got)
+	}
+}
+
+// TestVirtualPeerRoundTrip checks that requests addressed to virtual
+// entries reach the pool handler with the path of the original entry,
+// as groupcache2 builds request URLs: peer + BasePath + group/key.
+func TestVirtualPeerRoundTrip(t *testing.T) {
+	const basePath = "/_groupcache/"
+	const groupKey = "group/some%2Fkey"
+
+	for _, addr := range []string{
+		"http://10.0.0.1:5000",
+		"https://[fd00::1]:5000",
+		"https://pod-0.svc-headless.ns.svc:5000/cache", // PeerURLTemplate with base path
+	} {
+		var paths, rawPaths []string
+		handler := VirtualPeerHandler(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			rawPaths = append(rawPaths, r.URL.EscapedPath())
+		}))
+
+		entries := virtualPeers(addr, 3)
+		if len(slices.Compact(slices.Clone(entries))) != 3 {
+			t.Errorf("%s: virtual entries must be distinct: %v", addr, entries)
+		}
+
+		for _, e := range entries {
+			req := httptest.NewRequest(http.MethodGet, e+basePath+groupKey, nil)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		for i := 1; i < len(paths); i++ {
+			if paths[i] != paths[0] || rawPaths[i] != rawPaths[0] {
+				t.Errorf("%s: entry %d: expected path %s (%s), got %s (%s)",
+					addr, i, paths[0], rawPaths[0], paths[i], rawPaths[i])
+			}
+		}
+	}
+}
+
+func TestPeerWeight(t *testing.T) {
+	g := newTestGroup(t, Options{WeightAnnotation: DefaultWeightAnnotation})
+	g.options.MaxWeight = 8
+
+	table := []struct {
+		annotation string
+		weight     int
+		want       int
+	}{
+		{"", 0, 1},
+		{"", 3, 3},
+		{"4", 3, 4},   // annotation wins
+		{"20", 0, 8},  // capped
+		{"-2", 2, 2},  // invalid annotation
+		{"big", 0, 1}, // invalid annotation
+	}
+	for _, data := range table {
+		p := PeerCandidate{Name: "pod", Weight: data.weight}
+		if data.annotation != "" {
+			p.Annotations = map[string]string{DefaultWeightAnnotation: data.annotation}
+		}
+		if got := g.peerWeight(p); got != data.want {
+			t.Errorf("annotation=%q weight=%d: expected %d, got %d",
+				data.annotation, data.weight, data.want, got)
+		}
+	}
+}